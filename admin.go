@@ -0,0 +1,161 @@
+package srvmon
+
+import (
+	"context"
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const maxAdminBody = 64 * 1024
+
+// adminServer implements the srvmon.v1.admin gRPC service on top of SrvMon.
+type adminServer struct {
+	m *SrvMon
+	pb.UnimplementedAdminServer
+}
+
+func (a *adminServer) SetOverride(_ context.Context, req *pb.SetOverrideRequest) (*pb.Override, error) {
+	if req.GetStatus() == pb.Status_STATUS_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+	if req.GetTtl().AsDuration() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl must not be negative")
+	}
+	return a.m.SetOverride(req.GetCheck(), req.GetStatus(), req.GetReason(), req.GetTtl().AsDuration()), nil
+}
+
+func (a *adminServer) ClearOverride(_ context.Context, req *pb.ClearOverrideRequest) (*pb.ClearOverrideResponse, error) {
+	return &pb.ClearOverrideResponse{Cleared: a.m.ClearOverride(req.GetCheck())}, nil
+}
+
+func (a *adminServer) SetMaintenance(_ context.Context, req *pb.SetMaintenanceRequest) (*pb.Override, error) {
+	if req.GetCheck() == "" {
+		return nil, status.Error(codes.InvalidArgument, "check is required")
+	}
+	if req.GetTtl().AsDuration() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl must not be negative")
+	}
+	return a.m.SetMaintenance(req.GetCheck(), req.GetReason(), req.GetTtl().AsDuration()), nil
+}
+
+// adminAuthorized reports whether the Authorization header value carries the admin token.
+func (m *SrvMon) adminAuthorized(header string) bool {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(m.adminToken)) == 1
+}
+
+// adminUnaryInterceptor rejects admin RPCs without a valid bearer token.
+func (m *SrvMon) adminUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !strings.HasPrefix(info.FullMethod, "/"+pb.Admin_ServiceDesc.ServiceName+"/") {
+		return handler(ctx, req)
+	}
+
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			header = v[0]
+		}
+	}
+	if !m.adminAuthorized(header) {
+		return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+
+	return handler(ctx, req)
+}
+
+// requireAdmin rejects admin REST requests without a valid bearer token.
+func (m *SrvMon) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.adminAuthorized(r.Header.Get("Authorization")) {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// registerAdminREST mounts the admin API under /admin.
+func (m *SrvMon) registerAdminREST(router *mux.Router) {
+	admin := &adminServer{m: m}
+
+	r := router.PathPrefix("/admin").Subrouter()
+	r.Use(m.requireAdmin)
+
+	r.HandleFunc("/overrides", func(w http.ResponseWriter, r *http.Request) {
+		req := &pb.SetOverrideRequest{}
+		if !m.readProto(w, r, req) {
+			return
+		}
+		resp, err := admin.SetOverride(r.Context(), req)
+		m.writeProto(w, resp, err)
+	}).Methods(http.MethodPost)
+
+	clearHandler := func(w http.ResponseWriter, r *http.Request) {
+		resp, err := admin.ClearOverride(r.Context(), &pb.ClearOverrideRequest{Check: mux.Vars(r)["check"]})
+		m.writeProto(w, resp, err)
+	}
+	r.HandleFunc("/overrides", clearHandler).Methods(http.MethodDelete)
+	r.HandleFunc("/overrides/{check}", clearHandler).Methods(http.MethodDelete)
+
+	r.HandleFunc("/maintenance", func(w http.ResponseWriter, r *http.Request) {
+		req := &pb.SetMaintenanceRequest{}
+		if !m.readProto(w, r, req) {
+			return
+		}
+		resp, err := admin.SetMaintenance(r.Context(), req)
+		m.writeProto(w, resp, err)
+	}).Methods(http.MethodPost)
+}
+
+// readProto decodes a JSON request body into msg, replying 400 on failure.
+// An empty body leaves msg unchanged.
+func (m *SrvMon) readProto(w http.ResponseWriter, r *http.Request, msg proto.Message) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminBody))
+	if err == nil && len(body) > 0 {
+		err = protojson.Unmarshal(body, msg)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeProto encodes msg as JSON, or maps a gRPC status error onto an HTTP error.
+func (m *SrvMon) writeProto(w http.ResponseWriter, msg proto.Message, err error) {
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			code = http.StatusBadRequest
+		case codes.NotFound:
+			code = http.StatusNotFound
+		}
+		http.Error(w, status.Convert(err).Message(), code)
+		return
+	}
+
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		m.log.Error("write response", zap.Error(err))
+	}
+}
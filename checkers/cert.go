@@ -0,0 +1,282 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	defaultCertWarning  = 14 * 24 * time.Hour
+	defaultCertCritical = 3 * 24 * time.Hour
+)
+
+// CertChecker watches certificate expiry, either of the chain a TLS endpoint
+// presents or of a PEM file or bundle on disk. The earliest expiry in the
+// chain decides: DEGRADED inside the warning window, DOWN inside the critical
+// window or once expired. A chain that fails verification is DOWN as well.
+type CertChecker struct {
+	name string
+	addr string
+	path string
+	must bool
+
+	timeout    time.Duration
+	serverName string
+	roots      *x509.CertPool
+	warning    time.Duration
+	critical   time.Duration
+	expiryOnly bool
+}
+
+type CertOption func(*CertChecker)
+
+// WithCertTimeout sets the deadline for the TLS handshake. Default: 3s.
+func WithCertTimeout(d time.Duration) CertOption {
+	return func(c *CertChecker) { c.timeout = d }
+}
+
+// WithCertServerName sets the SNI and the host name the leaf certificate is
+// verified against. For endpoints it defaults to the host part of the
+// address; for files the host name is not verified unless this is set.
+func WithCertServerName(name string) CertOption {
+	return func(c *CertChecker) { c.serverName = name }
+}
+
+// WithCertRootCAs sets the CAs the chain is verified against.
+// Default: the system pool.
+func WithCertRootCAs(pool *x509.CertPool) CertOption {
+	return func(c *CertChecker) { c.roots = pool }
+}
+
+// WithExpiryWindows sets how long before expiry the check turns DEGRADED
+// (warning) and DOWN (critical). Default: 14 days and 3 days.
+func WithExpiryWindows(warning, critical time.Duration) CertOption {
+	return func(c *CertChecker) {
+		c.warning = warning
+		c.critical = critical
+	}
+}
+
+// WithExpiryOnly still reports chain and host name verification in the
+// details but judges the certificate on expiry alone.
+func WithExpiryOnly() CertOption {
+	return func(c *CertChecker) { c.expiryOnly = true }
+}
+
+// NewTLSCertChecker performs a TLS handshake with addr (host:port) and checks
+// the certificates the server presents.
+func NewTLSCertChecker(name, addr string, mustOK bool, opts ...CertOption) *CertChecker {
+	c := newCertChecker(name, mustOK, opts)
+	c.addr = addr
+	if c.serverName == "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			c.serverName = host
+		}
+	}
+	return c
+}
+
+// NewCertFileChecker checks the PEM encoded certificates at path. The first
+// certificate is taken as the leaf and the rest as its chain.
+func NewCertFileChecker(name, path string, mustOK bool, opts ...CertOption) *CertChecker {
+	c := newCertChecker(name, mustOK, opts)
+	c.path = path
+	return c
+}
+
+func newCertChecker(name string, mustOK bool, opts []CertOption) *CertChecker {
+	c := &CertChecker{
+		name:     name,
+		must:     mustOK,
+		timeout:  defaultTimeout,
+		warning:  defaultCertWarning,
+		critical: defaultCertCritical,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *CertChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	var (
+		certs []*x509.Certificate
+		err   error
+	)
+	if c.path != "" {
+		certs, err = readCerts(c.path)
+		if err != nil {
+			return fail(resp, "read certificates failed", err), nil
+		}
+	} else {
+		certs, err = c.handshake(ctx, resp)
+		if err != nil {
+			return fail(resp, "handshake failed", err), nil
+		}
+	}
+
+	leaf := certs[0]
+	resp.Details["subject"] = leaf.Subject.String()
+	resp.Details["issuer"] = leaf.Issuer.String()
+	resp.Details["sans"] = strings.Join(sans(leaf), ",")
+	resp.Details["not_after"] = leaf.NotAfter.UTC().Format(time.RFC3339)
+
+	now := time.Now()
+	earliest := leaf
+	for i, cert := range certs[1:] {
+		prefix := "chain." + strconv.Itoa(i+1) + "."
+		resp.Details[prefix+"subject"] = cert.Subject.String()
+		resp.Details[prefix+"not_after"] = cert.NotAfter.UTC().Format(time.RFC3339)
+		if cert.NotAfter.Before(earliest.NotAfter) {
+			earliest = cert
+		}
+	}
+	remaining := earliest.NotAfter.Sub(now)
+	resp.Details["expires_in"] = days(remaining)
+
+	verifyErr := c.verify(resp, certs, now)
+
+	switch {
+	case remaining <= 0:
+		return fail(resp, fmt.Sprintf("certificate %q expired %s ago", earliest.Subject.CommonName, days(-remaining)), nil), nil
+	case now.Before(leaf.NotBefore):
+		return fail(resp, "certificate not valid before "+leaf.NotBefore.UTC().Format(time.RFC3339), nil), nil
+	case remaining < c.critical:
+		return fail(resp, fmt.Sprintf("certificate %q expires in %s", earliest.Subject.CommonName, days(remaining)), nil), nil
+	case verifyErr != nil && !c.expiryOnly:
+		return fail(resp, "verification failed", verifyErr), nil
+	case remaining < c.warning:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = fmt.Sprintf("certificate %q expires in %s", earliest.Subject.CommonName, days(remaining))
+	default:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = "expires in " + days(remaining)
+	}
+	if verifyErr != nil {
+		resp.Error = verifyErr.Error()
+	}
+	return resp, nil
+}
+
+// handshake returns the certificates presented by the endpoint. Verification
+// is done separately so that expired or untrusted chains can still be reported.
+func (c *CertChecker) handshake(ctx context.Context, resp *pb.CheckResult) ([]*x509.Certificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	d := tls.Dialer{Config: &tls.Config{
+		ServerName:         c.serverName,
+		InsecureSkipVerify: true,
+	}}
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	resp.Details["tls_version"] = tls.VersionName(state.Version)
+	if len(state.PeerCertificates) == 0 {
+		return nil, errors.New("no certificates presented")
+	}
+	return state.PeerCertificates, nil
+}
+
+// verify checks the chain and the host name and records both outcomes.
+func (c *CertChecker) verify(resp *pb.CheckResult, certs []*x509.Certificate, now time.Time) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	var errs []error
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	resp.Details["chain_verified"] = strconv.FormatBool(err == nil)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.serverName == "" {
+		resp.Details["hostname_verified"] = "skipped"
+	} else {
+		err := certs[0].VerifyHostname(c.serverName)
+		resp.Details["hostname_verified"] = strconv.FormatBool(err == nil)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func readCerts(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates in %s", path)
+	}
+	return certs, nil
+}
+
+func sans(cert *x509.Certificate) []string {
+	names := append([]string(nil), cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	return append(names, cert.EmailAddresses...)
+}
+
+// days formats d in days and hours, e.g. 13d4h.
+func days(d time.Duration) string {
+	h := int(d.Hours())
+	return fmt.Sprintf("%dd%dh", h/24, h%24)
+}
+
+func (c *CertChecker) Name() string {
+	return c.name
+}
+
+func (c *CertChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
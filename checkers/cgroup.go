@@ -0,0 +1,28 @@
+package checkers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ownCgroup resolves the cgroup v2 directory named in proc/self/cgroup below
+// root, e.g. sys/fs/cgroup/system.slice/app.service, or returns "" when the
+// process is not in a cgroup v2 hierarchy.
+func ownCgroup(root string) string {
+	f, err := os.Open(filepath.Join(root, "proc", "self", "cgroup"))
+	if err != nil {
+		return ""
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if path, ok := strings.CutPrefix(sc.Text(), "0::"); ok {
+			return filepath.Join(root, "sys", "fs", "cgroup", path)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+// Package checkers provides ready-made srvmon.Checker implementations for
+// common dependencies. Every checker reports failures as a DOWN (or DEGRADED)
+// result rather than an error, and records its observations in the result details.
+package checkers
+
+import (
+	"strconv"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultTimeout = 3 * time.Second
+
+func newResult(name string) *pb.CheckResult {
+	return &pb.CheckResult{
+		Name:      name,
+		Timestamp: timestamppb.New(time.Now()),
+		Details:   make(map[string]string),
+	}
+}
+
+// fail marks resp as DOWN with a message and the error that caused it.
+func fail(resp *pb.CheckResult, msg string, err error) *pb.CheckResult {
+	resp.Status = pb.Status_STATUS_DOWN
+	resp.Message = msg
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// latency formats d for result details.
+func latency(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
+
+// percent returns part as a percentage of total, or 0 for an empty total.
+func percent(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// formatPercent formats a percentage for result details and messages.
+func formatPercent(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,280 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// DialChecker checks that an endpoint accepts connections over TCP, UDP or a
+// Unix domain socket.
+type DialChecker struct {
+	name    string
+	network string
+	addr    string
+	must    bool
+
+	timeout  time.Duration
+	source   net.IP
+	prefer   int
+	quorum   int
+	resolver *net.Resolver
+
+	send   []byte
+	expect []byte
+}
+
+type DialOption func(*DialChecker)
+
+// WithDialTimeout sets the deadline for resolving, dialing and, for UDP, the
+// send/expect exchange. Default: 3s.
+func WithDialTimeout(d time.Duration) DialOption {
+	return func(c *DialChecker) { c.timeout = d }
+}
+
+// WithSourceAddr binds outgoing connections to the given local IP.
+// Ignored for Unix sockets.
+func WithSourceAddr(ip net.IP) DialOption {
+	return func(c *DialChecker) { c.source = ip }
+}
+
+// PreferIPv4 dials IPv4 addresses before IPv6 ones.
+func PreferIPv4() DialOption {
+	return func(c *DialChecker) { c.prefer = 4 }
+}
+
+// PreferIPv6 dials IPv6 addresses before IPv4 ones.
+func PreferIPv6() DialOption {
+	return func(c *DialChecker) { c.prefer = 6 }
+}
+
+// WithReachable dials every resolved address and requires at least n of them
+// to be reachable. The check is DEGRADED when some, but not too many, fail.
+// By default the first reachable address is enough.
+func WithReachable(n int) DialOption {
+	return func(c *DialChecker) { c.quorum = n }
+}
+
+// WithResolver sets the resolver used for host names. Default: net.DefaultResolver.
+func WithResolver(r *net.Resolver) DialOption {
+	return func(c *DialChecker) { c.resolver = r }
+}
+
+// NewTCPChecker checks that addr (host:port) accepts TCP connections.
+func NewTCPChecker(name, addr string, mustOK bool, opts ...DialOption) *DialChecker {
+	return newDialChecker(name, "tcp", addr, mustOK, opts)
+}
+
+// NewUnixChecker checks that the Unix domain socket at path accepts connections.
+func NewUnixChecker(name, path string, mustOK bool, opts ...DialOption) *DialChecker {
+	return newDialChecker(name, "unix", path, mustOK, opts)
+}
+
+// NewUDPChecker sends the send payload to addr (host:port) and, if expect is
+// not empty, requires a reply containing it.
+func NewUDPChecker(name, addr string, send, expect []byte, mustOK bool, opts ...DialOption) *DialChecker {
+	c := newDialChecker(name, "udp", addr, mustOK, opts)
+	c.send = send
+	c.expect = expect
+	return c
+}
+
+func newDialChecker(name, network, addr string, mustOK bool, opts []DialOption) *DialChecker {
+	c := &DialChecker{
+		name:     name,
+		network:  network,
+		addr:     addr,
+		must:     mustOK,
+		timeout:  defaultTimeout,
+		resolver: net.DefaultResolver,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// dialResult is the outcome of dialing one resolved address.
+type dialResult struct {
+	addr    string
+	latency time.Duration
+	err     error
+}
+
+func (c *DialChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	addrs, err := c.resolve(ctx)
+	if err != nil {
+		return fail(resp, "resolve failed", err), nil
+	}
+	resp.Details["resolved"] = strings.Join(addrs, ",")
+
+	if c.quorum > 0 {
+		return c.checkQuorum(ctx, resp, addrs), nil
+	}
+
+	var errs []error
+	for _, addr := range addrs {
+		r := c.dial(ctx, addr)
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = "connection successful"
+		resp.Details["address"] = r.addr
+		resp.Details["latency"] = latency(r.latency)
+		return resp, nil
+	}
+
+	return fail(resp, "connection failed", errors.Join(errs...)), nil
+}
+
+// checkQuorum dials all addresses concurrently and compares the number of
+// reachable ones against the configured quorum.
+func (c *DialChecker) checkQuorum(ctx context.Context, resp *pb.CheckResult, addrs []string) *pb.CheckResult {
+	results := make([]dialResult, len(addrs))
+
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.dial(ctx, addr)
+		}()
+	}
+	wg.Wait()
+
+	var (
+		reachable int
+		errs      []error
+	)
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			resp.Details["latency."+r.addr] = "unreachable"
+			continue
+		}
+		reachable++
+		resp.Details["latency."+r.addr] = latency(r.latency)
+	}
+	resp.Details["reachable"] = fmt.Sprintf("%d/%d", reachable, len(addrs))
+
+	switch {
+	case reachable < c.quorum:
+		fail(resp, fmt.Sprintf("%d/%d addresses reachable, need %d", reachable, len(addrs), c.quorum), errors.Join(errs...))
+	case reachable < len(addrs):
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = fmt.Sprintf("%d/%d addresses reachable", reachable, len(addrs))
+		resp.Error = errors.Join(errs...).Error()
+	default:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = fmt.Sprintf("%d/%d addresses reachable", reachable, len(addrs))
+	}
+	return resp
+}
+
+// resolve expands the host part of addr into ip:port pairs ordered by the
+// configured address family preference. Unix socket paths are returned as is.
+func (c *DialChecker) resolve(ctx context.Context) ([]string, error) {
+	if c.network == "unix" {
+		return []string{c.addr}, nil
+	}
+
+	host, port, err := net.SplitHostPort(c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := c.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+
+	var first, rest []string
+	for _, ip := range ips {
+		addr := net.JoinHostPort(ip.String(), port)
+		if c.prefer == 0 || (ip.IP.To4() != nil) == (c.prefer == 4) {
+			first = append(first, addr)
+		} else {
+			rest = append(rest, addr)
+		}
+	}
+	return append(first, rest...), nil
+}
+
+func (c *DialChecker) dial(ctx context.Context, addr string) dialResult {
+	d := net.Dialer{}
+	if c.source != nil {
+		switch c.network {
+		case "tcp":
+			d.LocalAddr = &net.TCPAddr{IP: c.source}
+		case "udp":
+			d.LocalAddr = &net.UDPAddr{IP: c.source}
+		}
+	}
+
+	start := time.Now()
+	conn, err := d.DialContext(ctx, c.network, addr)
+	if err != nil {
+		return dialResult{addr: addr, err: err}
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if c.network == "udp" {
+		if err := c.exchange(ctx, conn); err != nil {
+			return dialResult{addr: addr, err: fmt.Errorf("%s: %w", addr, err)}
+		}
+	}
+
+	return dialResult{addr: addr, latency: time.Since(start)}
+}
+
+// exchange sends the UDP payload and waits for the expected reply.
+func (c *DialChecker) exchange(ctx context.Context, conn net.Conn) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(c.send); err != nil {
+		return err
+	}
+	if len(c.expect) == 0 {
+		return nil
+	}
+
+	buf := make([]byte, 64*1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(buf[:n], c.expect) {
+		return fmt.Errorf("unexpected reply %s", strconv.Quote(string(buf[:n])))
+	}
+	return nil
+}
+
+func (c *DialChecker) Name() string {
+	return c.name
+}
+
+func (c *DialChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
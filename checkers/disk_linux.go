@@ -0,0 +1,291 @@
+//go:build linux
+
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	defaultDiskWarning  = 10
+	defaultDiskCritical = 5
+	// stRdonly is ST_RDONLY in statfs flags.
+	stRdonly = 0x1
+)
+
+// DiskChecker reports free space and free inodes of the filesystems holding
+// the configured paths, as seen by statfs. Falling under a warning threshold
+// makes a path DEGRADED, under a critical one DOWN. With WithWriteCanary it
+// also writes, fsyncs, reads back and deletes a small file in every path to
+// catch read-only or hung mounts. Several paths are reported as nested
+// results and the check takes the worst of them. NewDiskChecker panics
+// without paths.
+type DiskChecker struct {
+	name  string
+	paths []string
+	must  bool
+
+	timeout     time.Duration
+	warnPercent float64
+	critPercent float64
+	warnBytes   uint64
+	critBytes   uint64
+	warnInodes  float64
+	critInodes  float64
+	canary      bool
+
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+type DiskOption func(*DiskChecker)
+
+// WithDiskTimeout sets the deadline for statfs and the canary of every
+// path. A mount that does not answer in time is DOWN. Default: 3s.
+func WithDiskTimeout(d time.Duration) DiskOption {
+	return func(c *DiskChecker) { c.timeout = d }
+}
+
+// WithMinFreePercent sets the thresholds for the share of blocks available
+// to unprivileged users. Pseudo filesystems reporting no blocks are exempt.
+// Default: 10% and 5%.
+func WithMinFreePercent(warning, critical float64) DiskOption {
+	return func(c *DiskChecker) {
+		c.warnPercent = warning
+		c.critPercent = critical
+	}
+}
+
+// WithMinFreeBytes sets thresholds on the bytes available to unprivileged
+// users. Default: none.
+func WithMinFreeBytes(warning, critical uint64) DiskOption {
+	return func(c *DiskChecker) {
+		c.warnBytes = warning
+		c.critBytes = critical
+	}
+}
+
+// WithMinFreeInodesPercent sets the thresholds for the share of free inodes.
+// Filesystems that do not report inodes (btrfs, some FUSE mounts) are exempt.
+// Default: 10% and 5%.
+func WithMinFreeInodesPercent(warning, critical float64) DiskOption {
+	return func(c *DiskChecker) {
+		c.warnInodes = warning
+		c.critInodes = critical
+	}
+}
+
+// WithWriteCanary writes, fsyncs, reads back and deletes a temporary file in
+// every path on each check.
+func WithWriteCanary() DiskOption {
+	return func(c *DiskChecker) { c.canary = true }
+}
+
+func NewDiskChecker(name string, paths []string, mustOK bool, opts ...DiskOption) *DiskChecker {
+	if len(paths) == 0 {
+		panic("checkers: disk checker " + name + " has no paths")
+	}
+	c := &DiskChecker{
+		name:        name,
+		paths:       paths,
+		must:        mustOK,
+		timeout:     defaultTimeout,
+		warnPercent: defaultDiskWarning,
+		critPercent: defaultDiskCritical,
+		warnInodes:  defaultDiskWarning,
+		critInodes:  defaultDiskCritical,
+		pending:     make(map[string]bool),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *DiskChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if len(c.paths) == 1 {
+		return c.checkPath(ctx, c.name, c.paths[0]), nil
+	}
+
+	children := make([]*pb.CheckResult, len(c.paths))
+	var wg sync.WaitGroup
+	for i, path := range c.paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			children[i] = c.checkPath(ctx, path, path)
+		}()
+	}
+	wg.Wait()
+
+	resp, healthy := srvmon.WorstOf(c.name, children)
+	resp.Message = fmt.Sprintf("%d/%d paths healthy", healthy, len(children))
+	return resp, nil
+}
+
+// diskProbe is what one statfs call and canary round-trip observed.
+type diskProbe struct {
+	stat   syscall.Statfs_t
+	canary time.Duration
+	err    error
+	op     string
+}
+
+// checkPath probes path in a goroutine so that a hung mount cannot block the
+// check past its deadline. The goroutine may outlive the check; until it
+// returns, later checks of the same path report it as still pending instead
+// of piling up further blocked probes.
+func (c *DiskChecker) checkPath(ctx context.Context, name, path string) *pb.CheckResult {
+	resp := newResult(name)
+	resp.Details["path"] = path
+
+	c.mu.Lock()
+	if c.pending[path] {
+		c.mu.Unlock()
+		return fail(resp, "previous probe still pending", nil)
+	}
+	c.pending[path] = true
+	c.mu.Unlock()
+
+	done := make(chan diskProbe, 1)
+	go func() {
+		p := c.probe(path)
+		c.mu.Lock()
+		delete(c.pending, path)
+		c.mu.Unlock()
+		done <- p
+	}()
+
+	var p diskProbe
+	select {
+	case p = <-done:
+	case <-ctx.Done():
+		return fail(resp, "filesystem not responding", ctx.Err())
+	}
+	if p.err != nil {
+		return fail(resp, p.op+" failed", p.err)
+	}
+
+	st := p.stat
+	bsize := uint64(st.Bsize)
+	free := st.Bavail * bsize
+	freePct := percent(st.Bavail, st.Blocks)
+	resp.Details["total_bytes"] = strconv.FormatUint(st.Blocks*bsize, 10)
+	resp.Details["free_bytes"] = strconv.FormatUint(free, 10)
+	resp.Details["free_percent"] = formatPercent(freePct)
+	resp.Details["read_only"] = strconv.FormatBool(st.Flags&stRdonly != 0)
+	if st.Files > 0 {
+		resp.Details["free_inodes"] = strconv.FormatUint(st.Ffree, 10)
+		resp.Details["free_inodes_percent"] = formatPercent(percent(st.Ffree, st.Files))
+	}
+	if c.canary {
+		resp.Details["canary_latency"] = latency(p.canary)
+	}
+
+	var critical, warning []string
+	if st.Blocks > 0 {
+		switch {
+		case freePct < c.critPercent:
+			critical = append(critical, "free space "+formatPercent(freePct)+" below "+formatPercent(c.critPercent))
+		case freePct < c.warnPercent:
+			warning = append(warning, "free space "+formatPercent(freePct)+" below "+formatPercent(c.warnPercent))
+		}
+		switch {
+		case free < c.critBytes:
+			critical = append(critical, fmt.Sprintf("free bytes %d below %d", free, c.critBytes))
+		case free < c.warnBytes:
+			warning = append(warning, fmt.Sprintf("free bytes %d below %d", free, c.warnBytes))
+		}
+	}
+	if st.Files > 0 {
+		inodesPct := percent(st.Ffree, st.Files)
+		switch {
+		case inodesPct < c.critInodes:
+			critical = append(critical, "free inodes "+formatPercent(inodesPct)+" below "+formatPercent(c.critInodes))
+		case inodesPct < c.warnInodes:
+			warning = append(warning, "free inodes "+formatPercent(inodesPct)+" below "+formatPercent(c.warnInodes))
+		}
+	}
+
+	switch {
+	case len(critical) > 0:
+		return fail(resp, strings.Join(critical, ", "), nil)
+	case len(warning) > 0:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = strings.Join(warning, ", ")
+	default:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = formatPercent(freePct) + " free"
+	}
+	return resp
+}
+
+func (c *DiskChecker) probe(path string) diskProbe {
+	var p diskProbe
+	if err := syscall.Statfs(path, &p.stat); err != nil {
+		p.err, p.op = err, "statfs"
+		return p
+	}
+	if c.canary {
+		start := time.Now()
+		if err := writeCanary(path); err != nil {
+			p.err, p.op = err, "write canary"
+			return p
+		}
+		p.canary = time.Since(start)
+	}
+	return p
+}
+
+// writeCanary writes a file in dir, fsyncs it, reads it back and removes it.
+func writeCanary(dir string) (err error) {
+	f, err := os.CreateTemp(dir, ".srvmon-canary-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		if rmErr := os.Remove(f.Name()); err == nil {
+			err = rmErr
+		}
+	}()
+
+	payload := []byte("srvmon canary " + time.Now().UTC().Format(time.RFC3339Nano))
+	if _, err := f.Write(payload); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	got := make([]byte, len(payload))
+	if _, err := f.ReadAt(got, 0); err != nil {
+		return err
+	}
+	if !bytes.Equal(got, payload) {
+		return errors.New("read back different content")
+	}
+	return nil
+}
+
+func (c *DiskChecker) Name() string {
+	return c.name
+}
+
+func (c *DiskChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
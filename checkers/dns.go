@@ -0,0 +1,240 @@
+package checkers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// RecordType selects the DNS records a DNSChecker resolves.
+type RecordType string
+
+const (
+	RecordA     RecordType = "A"
+	RecordAAAA  RecordType = "AAAA"
+	RecordCNAME RecordType = "CNAME"
+	RecordSRV   RecordType = "SRV"
+	RecordTXT   RecordType = "TXT"
+)
+
+// DNSChecker resolves one name and asserts on the answers. Answers are
+// compared in their textual form: IPs for A and AAAA, the canonical name for
+// CNAME, "priority weight port target" for SRV and the record text for TXT.
+// Names are compared without the trailing dot and case-insensitively.
+type DNSChecker struct {
+	name  string
+	host  string
+	rtype RecordType
+	must  bool
+
+	timeout  time.Duration
+	server   string
+	resolver *net.Resolver
+
+	minRecords int
+	expect     []string
+	pinned     []string
+}
+
+type DNSOption func(*DNSChecker)
+
+// WithDNSTimeout sets the deadline for the lookup. Default: 3s.
+func WithDNSTimeout(d time.Duration) DNSOption {
+	return func(c *DNSChecker) { c.timeout = d }
+}
+
+// WithDNSServer sends queries to the DNS server at addr (host:port) instead
+// of the system resolvers.
+func WithDNSServer(addr string) DNSOption {
+	return func(c *DNSChecker) { c.server = addr }
+}
+
+// WithMinRecords requires at least n answers. Default: 1.
+func WithMinRecords(n int) DNSOption {
+	return func(c *DNSChecker) { c.minRecords = n }
+}
+
+// WithExpectRecords requires every value to be among the answers.
+func WithExpectRecords(values ...string) DNSOption {
+	return func(c *DNSChecker) { c.expect = values }
+}
+
+// WithPinnedRecords pins the expected answer set. Any answer added or missing
+// compared to it makes the check DEGRADED.
+func WithPinnedRecords(values ...string) DNSOption {
+	return func(c *DNSChecker) { c.pinned = values }
+}
+
+func NewDNSChecker(name, host string, rtype RecordType, mustOK bool, opts ...DNSOption) *DNSChecker {
+	c := &DNSChecker{
+		name:       name,
+		host:       host,
+		rtype:      rtype,
+		must:       mustOK,
+		timeout:    defaultTimeout,
+		resolver:   net.DefaultResolver,
+		minRecords: 1,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	c.expect = c.normalize(c.expect)
+	if c.pinned != nil {
+		c.pinned = c.normalize(c.pinned)
+	}
+
+	if c.server != "" {
+		c.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, c.server)
+			},
+		}
+	}
+
+	return c
+}
+
+func (c *DNSChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+	resp.Details["type"] = string(c.rtype)
+	if c.server != "" {
+		resp.Details["server"] = c.server
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	answers, err := c.lookup(ctx)
+	resp.Details["latency"] = latency(time.Since(start))
+	if err != nil {
+		return fail(resp, "resolve failed", err), nil
+	}
+
+	answers = c.normalize(answers)
+	resp.Details["answers"] = strings.Join(answers, ", ")
+	resp.Details["count"] = strconv.Itoa(len(answers))
+
+	if len(answers) < c.minRecords {
+		return fail(resp, fmt.Sprintf("%d records, need %d", len(answers), c.minRecords), nil), nil
+	}
+
+	var missing []string
+	for _, want := range c.expect {
+		if !slices.Contains(answers, want) {
+			missing = append(missing, want)
+		}
+	}
+	if len(missing) > 0 {
+		return fail(resp, "missing expected records: "+strings.Join(missing, ", "), nil), nil
+	}
+
+	if c.pinned != nil && !slices.Equal(answers, c.pinned) {
+		var diff []string
+		for _, a := range answers {
+			if !slices.Contains(c.pinned, a) {
+				diff = append(diff, "+"+a)
+			}
+		}
+		for _, p := range c.pinned {
+			if !slices.Contains(answers, p) {
+				diff = append(diff, "-"+p)
+			}
+		}
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = "answers changed: " + strings.Join(diff, " ")
+		return resp, nil
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = fmt.Sprintf("%d %s records", len(answers), c.rtype)
+	return resp, nil
+}
+
+func (c *DNSChecker) lookup(ctx context.Context) ([]string, error) {
+	switch c.rtype {
+	case RecordA, RecordAAAA:
+		network := "ip4"
+		if c.rtype == RecordAAAA {
+			network = "ip6"
+		}
+		ips, err := c.resolver.LookupIP(ctx, network, c.host)
+		if err != nil {
+			return nil, err
+		}
+		answers := make([]string, len(ips))
+		for i, ip := range ips {
+			answers[i] = ip.String()
+		}
+		return answers, nil
+
+	case RecordCNAME:
+		cname, err := c.resolver.LookupCNAME(ctx, c.host)
+		if err != nil {
+			return nil, err
+		}
+		return []string{cname}, nil
+
+	case RecordSRV:
+		_, srvs, err := c.resolver.LookupSRV(ctx, "", "", c.host)
+		if err != nil {
+			return nil, err
+		}
+		answers := make([]string, len(srvs))
+		for i, srv := range srvs {
+			answers[i] = fmt.Sprintf("%d %d %d %s", srv.Priority, srv.Weight, srv.Port, srv.Target)
+		}
+		return answers, nil
+
+	case RecordTXT:
+		return c.resolver.LookupTXT(ctx, c.host)
+
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", c.rtype)
+	}
+}
+
+// normalize puts IPs in canonical form, lowercases names and drops their
+// trailing dot, then sorts the answers so that sets can be compared. TXT
+// values are kept as they are.
+func (c *DNSChecker) normalize(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		switch c.rtype {
+		case RecordA, RecordAAAA:
+			if ip := net.ParseIP(v); ip != nil {
+				v = ip.String()
+			}
+		case RecordCNAME:
+			v = normalizeName(v)
+		case RecordSRV:
+			if fields := strings.Fields(v); len(fields) == 4 {
+				fields[3] = normalizeName(fields[3])
+				v = strings.Join(fields, " ")
+			}
+		}
+		out[i] = v
+	}
+	slices.Sort(out)
+	return out
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+func (c *DNSChecker) Name() string {
+	return c.name
+}
+
+func (c *DNSChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
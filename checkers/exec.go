@@ -0,0 +1,215 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const maxExecOutput = 64 * 1024
+
+// ExecChecker runs a Nagios/Monitoring Plugins compatible command and maps its
+// exit code onto the result: 0 is UP, 1 DEGRADED, 2 DOWN and anything else
+// UNKNOWN. The first line of standard output becomes the message, further
+// lines are reported as output and the performance data after "|" is parsed
+// into perf.<label> details. On timeout the command's whole process group is
+// killed (on Unix) and the check is DOWN.
+type ExecChecker struct {
+	name string
+	path string
+	args []string
+	must bool
+
+	timeout time.Duration
+	env     []string
+	dir     string
+}
+
+type ExecOption func(*ExecChecker)
+
+// WithExecTimeout sets the deadline after which the command is killed.
+// Default: 10s.
+func WithExecTimeout(d time.Duration) ExecOption {
+	return func(c *ExecChecker) { c.timeout = d }
+}
+
+// WithExecEnv adds "KEY=value" pairs to the environment the command inherits
+// from the current process.
+func WithExecEnv(env ...string) ExecOption {
+	return func(c *ExecChecker) { c.env = append(c.env, env...) }
+}
+
+// WithExecDir sets the command's working directory. Default: the current one.
+func WithExecDir(dir string) ExecOption {
+	return func(c *ExecChecker) { c.dir = dir }
+}
+
+func NewExecChecker(name, path string, args []string, mustOK bool, opts ...ExecOption) *ExecChecker {
+	c := &ExecChecker{name: name, path: path, args: args, must: mustOK, timeout: 10 * time.Second}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *ExecChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	runCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, c.path, c.args...)
+	cmd.Dir = c.dir
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
+	}
+	var stdout, stderr cappedBuffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// Don't wait for grandchildren still holding the pipes once killed.
+	cmd.WaitDelay = time.Second
+	setProcessGroup(cmd)
+
+	start := time.Now()
+	err := cmd.Run()
+	resp.Details["latency"] = latency(time.Since(start))
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return fail(resp, "canceled", ctx.Err()), nil
+	case runCtx.Err() != nil:
+		return fail(resp, "killed after "+c.timeout.String(), runCtx.Err()), nil
+	case errors.Is(err, exec.ErrWaitDelay):
+		// The plugin exited but a child it forked still held the pipes.
+	case err != nil && !errors.As(err, &exitErr):
+		return fail(resp, "start failed", err), nil
+	}
+
+	code := cmd.ProcessState.ExitCode()
+	resp.Details["exit_code"] = strconv.Itoa(code)
+	switch code {
+	case 0:
+		resp.Status = pb.Status_STATUS_UP
+	case 1:
+		resp.Status = pb.Status_STATUS_DEGRADED
+	case 2:
+		resp.Status = pb.Status_STATUS_DOWN
+	default:
+		resp.Status = pb.Status_STATUS_UNKNOWN
+	}
+	if code != 0 && stderr.Len() > 0 {
+		resp.Error = strings.TrimSpace(stderr.String())
+	}
+
+	text, long, perf := splitPluginOutput(stdout.String())
+	resp.Message = text
+	if resp.Message == "" {
+		resp.Message = "exit code " + strconv.Itoa(code)
+	}
+	if long != "" {
+		resp.Details["output"] = long
+	}
+	parsePerfdata(perf, resp.Details)
+
+	return resp, nil
+}
+
+// splitPluginOutput splits plugin output into the first line's text, the
+// remaining lines and the performance data, which follows a "|" on the first
+// line and, for multi-line output, a second "|" in the remaining lines.
+func splitPluginOutput(out string) (text, long, perf string) {
+	first, rest, _ := strings.Cut(strings.TrimRight(out, "\n"), "\n")
+	text, perf, _ = strings.Cut(first, "|")
+	long, more, ok := strings.Cut(rest, "|")
+	if ok {
+		perf += " " + strings.ReplaceAll(more, "\n", " ")
+	}
+	return strings.TrimSpace(text), strings.TrimSpace(long), perf
+}
+
+// parsePerfdata parses "'label'=value[UOM];[warn];[crit];[min];[max]" items
+// into perf.<label> (value with unit) and perf.<label>.<warn|crit|min|max>
+// details. Malformed items are skipped.
+func parsePerfdata(perf string, details map[string]string) {
+	for perf = strings.TrimSpace(perf); perf != ""; perf = strings.TrimSpace(perf) {
+		var label string
+		if perf[0] == '\'' {
+			// Quoted labels may contain spaces; '' is an escaped quote.
+			var b strings.Builder
+			i := 1
+			for ; i < len(perf); i++ {
+				if perf[i] == '\'' {
+					if i+1 < len(perf) && perf[i+1] == '\'' {
+						b.WriteByte('\'')
+						i++
+						continue
+					}
+					break
+				}
+				b.WriteByte(perf[i])
+			}
+			label, perf = b.String(), perf[min(i+1, len(perf)):]
+			if !strings.HasPrefix(perf, "=") {
+				perf = skipItem(perf)
+				continue
+			}
+			perf = perf[1:]
+		} else {
+			l, v, ok := strings.Cut(perf, "=")
+			if !ok || strings.ContainsAny(l, " \t") {
+				perf = skipItem(perf)
+				continue
+			}
+			label, perf = l, v
+		}
+
+		item, rest, _ := strings.Cut(perf, " ")
+		perf = rest
+		fields := strings.Split(item, ";")
+		if label == "" || fields[0] == "" {
+			continue
+		}
+		key := "perf." + label
+		details[key] = fields[0]
+		for i, suffix := range []string{"warn", "crit", "min", "max"} {
+			if i+1 < len(fields) && fields[i+1] != "" {
+				details[key+"."+suffix] = fields[i+1]
+			}
+		}
+	}
+}
+
+// skipItem drops the malformed item at the start of perf.
+func skipItem(perf string) string {
+	_, rest, _ := strings.Cut(perf, " ")
+	return rest
+}
+
+// cappedBuffer keeps the first maxExecOutput bytes written to it and
+// silently discards the rest so a chatty command cannot block or exhaust
+// memory.
+type cappedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxExecOutput - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (c *ExecChecker) Name() string {
+	return c.name
+}
+
+func (c *ExecChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
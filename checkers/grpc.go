@@ -0,0 +1,278 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCChecker invokes an arbitrary unary method with a JSON request, for
+// upstreams that do not implement grpc.health.v1. The method's descriptors
+// come from gRPC server reflection (v1) or from a FileDescriptorSet. The call
+// is UP when it ends with an expected status code and the JSON form of the
+// response satisfies every WithGRPCJSONPath assertion.
+type GRPCChecker struct {
+	name   string
+	conn   *grpc.ClientConn
+	method string
+	must   bool
+
+	timeout time.Duration
+	request string
+	md      metadata.MD
+	set     *descriptorpb.FileDescriptorSet
+	codes   []codes.Code
+	fields  [][2]string
+
+	mu       sync.Mutex
+	resolved protoreflect.MethodDescriptor
+}
+
+type GRPCOption func(*GRPCChecker)
+
+// WithGRPCTimeout sets the deadline for resolving and invoking the method.
+// Default: 3s.
+func WithGRPCTimeout(d time.Duration) GRPCOption {
+	return func(c *GRPCChecker) { c.timeout = d }
+}
+
+// WithGRPCRequest sets the request message in protobuf JSON form.
+// Default: {}.
+func WithGRPCRequest(json string) GRPCOption {
+	return func(c *GRPCChecker) { c.request = json }
+}
+
+// WithGRPCMetadata adds an outgoing metadata entry to the call.
+func WithGRPCMetadata(key, value string) GRPCOption {
+	return func(c *GRPCChecker) { c.md.Append(key, value) }
+}
+
+// WithGRPCDescriptors resolves the method from set instead of asking the
+// server over reflection. set must hold the method's file and its imports.
+func WithGRPCDescriptors(set *descriptorpb.FileDescriptorSet) GRPCOption {
+	return func(c *GRPCChecker) { c.set = set }
+}
+
+// WithGRPCExpectCode accepts calls ending with any of the want codes.
+// Default: OK.
+func WithGRPCExpectCode(want ...codes.Code) GRPCOption {
+	return func(c *GRPCChecker) { c.codes = want }
+}
+
+// WithGRPCJSONPath requires the JSON form of the response to hold want at
+// path, using the same syntax as WithJSONPath. Fields use their JSON names,
+// e.g. $.user.displayName; enums are compared by name.
+func WithGRPCJSONPath(path, want string) GRPCOption {
+	return func(c *GRPCChecker) { c.fields = append(c.fields, [2]string{path, want}) }
+}
+
+// NewGRPCChecker calls method on conn. method is the full method name, e.g.
+// "acme.users.v1.Users/GetUser" or "/acme.users.v1.Users/GetUser".
+func NewGRPCChecker(name string, conn *grpc.ClientConn, method string, mustOK bool, opts ...GRPCOption) *GRPCChecker {
+	c := &GRPCChecker{
+		name:    name,
+		conn:    conn,
+		method:  strings.TrimPrefix(method, "/"),
+		must:    mustOK,
+		timeout: defaultTimeout,
+		request: "{}",
+		md:      metadata.MD{},
+		codes:   []codes.Code{codes.OK},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *GRPCChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	md, err := c.resolve(ctx)
+	if err != nil {
+		return fail(resp, "resolve method failed", err), nil
+	}
+
+	in := dynamicpb.NewMessage(md.Input())
+	if err := protojson.Unmarshal([]byte(c.request), in); err != nil {
+		return fail(resp, "invalid request", err), nil
+	}
+	out := dynamicpb.NewMessage(md.Output())
+
+	start := time.Now()
+	err = c.conn.Invoke(metadata.NewOutgoingContext(ctx, c.md), "/"+c.method, in, out)
+	code := status.Code(err)
+	resp.Details["latency"] = latency(time.Since(start))
+	resp.Details["code"] = code.String()
+
+	if !c.expected(code) {
+		return fail(resp, "unexpected status "+code.String(), err), nil
+	}
+
+	if code == codes.OK && len(c.fields) > 0 {
+		body, err := protojson.Marshal(out)
+		if err != nil {
+			return fail(resp, "encode response failed", err), nil
+		}
+		var errs []error
+		for _, f := range c.fields {
+			got, err := jsonPath(body, f[0])
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s: %w", f[0], err))
+			case got != f[1]:
+				errs = append(errs, fmt.Errorf("%s = %s, want %s", f[0], strconv.Quote(got), strconv.Quote(f[1])))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return fail(resp, "assertion failed", err), nil
+		}
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = code.String()
+	return resp, nil
+}
+
+func (c *GRPCChecker) expected(code codes.Code) bool {
+	for _, want := range c.codes {
+		if code == want {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve finds the method descriptor once; a failed lookup is retried on
+// the next check.
+func (c *GRPCChecker) resolve(ctx context.Context) (protoreflect.MethodDescriptor, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resolved != nil {
+		return c.resolved, nil
+	}
+
+	service, method, ok := strings.Cut(c.method, "/")
+	if !ok {
+		return nil, fmt.Errorf("method %q is not of the form package.Service/Method", c.method)
+	}
+
+	set := c.set
+	if set == nil {
+		var err error
+		if set, err = reflectFiles(ctx, c.conn, service); err != nil {
+			return nil, err
+		}
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, err
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", service, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	switch {
+	case md == nil:
+		return nil, fmt.Errorf("service %s has no method %s", service, method)
+	case md.IsStreamingClient() || md.IsStreamingServer():
+		return nil, fmt.Errorf("%s is a streaming method", c.method)
+	}
+
+	c.resolved = md
+	return md, nil
+}
+
+// reflectFiles asks the server for the file defining symbol and then for
+// every import the server did not send along with it.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, symbol string) (*descriptorpb.FileDescriptorSet, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	set := &descriptorpb.FileDescriptorSet{}
+	ask := func(req *rpb.ServerReflectionRequest) error {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+		r, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if e := r.GetErrorResponse(); e != nil {
+			return status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+		}
+		for _, raw := range r.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return err
+			}
+			if _, ok := files[fd.GetName()]; !ok {
+				files[fd.GetName()] = fd
+				set.File = append(set.File, fd)
+			}
+		}
+		return nil
+	}
+
+	if err := ask(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}); err != nil {
+		return nil, fmt.Errorf("reflection: %w", err)
+	}
+	for i := 0; i < len(set.File); i++ {
+		for _, dep := range set.File[i].GetDependency() {
+			if _, ok := files[dep]; ok {
+				continue
+			}
+			if err := ask(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			}); err != nil {
+				return nil, fmt.Errorf("reflection: %s: %w", dep, err)
+			}
+			if _, ok := files[dep]; !ok {
+				return nil, fmt.Errorf("reflection: server did not send %s", dep)
+			}
+		}
+	}
+
+	return set, nil
+}
+
+func (c *GRPCChecker) Name() string {
+	return c.name
+}
+
+func (c *GRPCChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
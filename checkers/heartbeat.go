@@ -0,0 +1,147 @@
+package checkers
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// HeartbeatChecker is a dead man's switch for background loops such as
+// outbox relays, schedulers and consumers. The loop calls Beat (or BeatWith)
+// every time it makes progress; the check turns DEGRADED and then DOWN when
+// no beat arrived within the configured intervals. Until the first beat the
+// intervals count from the checker's creation. It is safe for concurrent use.
+type HeartbeatChecker struct {
+	name string
+	must bool
+
+	degradedAfter time.Duration
+	downAfter     time.Duration
+
+	mu       sync.Mutex
+	created  time.Time
+	last     time.Time
+	beats    uint64
+	progress map[string]string
+	recent   [beatRateWindow]beatBucket
+}
+
+// beatRateWindow is the number of one-second buckets beat_rate averages over.
+const beatRateWindow = 60
+
+// beatBucket counts the beats recorded during one second.
+type beatBucket struct {
+	sec   int64
+	beats uint64
+}
+
+type HeartbeatOption func(*HeartbeatChecker)
+
+// WithBeatIntervals sets how long after the last beat the check turns
+// DEGRADED and DOWN. Default: 1m and 5m.
+func WithBeatIntervals(degraded, down time.Duration) HeartbeatOption {
+	return func(c *HeartbeatChecker) {
+		c.degradedAfter = degraded
+		c.downAfter = down
+	}
+}
+
+func NewHeartbeatChecker(name string, mustOK bool, opts ...HeartbeatOption) *HeartbeatChecker {
+	now := time.Now()
+	c := &HeartbeatChecker{
+		name:          name,
+		must:          mustOK,
+		degradedAfter: time.Minute,
+		downAfter:     5 * time.Minute,
+		created:       now,
+		progress:      make(map[string]string),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Beat records that the loop is alive.
+func (c *HeartbeatChecker) Beat() {
+	c.BeatWith(nil)
+}
+
+// BeatWith records a beat together with progress metadata such as an offset
+// or the number of items processed. Keys are reported as progress.<key> and
+// keep their latest value.
+func (c *HeartbeatChecker) BeatWith(progress map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = time.Now()
+	c.beats++
+	sec := c.last.Unix()
+	b := &c.recent[sec%beatRateWindow]
+	if b.sec != sec {
+		*b = beatBucket{sec: sec}
+	}
+	b.beats++
+	maps.Copy(c.progress, progress)
+}
+
+func (c *HeartbeatChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+	now := time.Now()
+
+	c.mu.Lock()
+	last, beats := c.last, c.beats
+	for k, v := range c.progress {
+		resp.Details["progress."+k] = v
+	}
+	var recent uint64
+	for _, b := range c.recent {
+		if now.Unix()-b.sec < beatRateWindow {
+			recent += b.beats
+		}
+	}
+	c.mu.Unlock()
+
+	resp.Details["beats"] = strconv.FormatUint(beats, 10)
+	if window := min(now.Sub(c.created), beatRateWindow*time.Second); window > 0 {
+		rate := float64(recent) / window.Seconds()
+		resp.Details["beat_rate"] = strconv.FormatFloat(rate, 'f', 2, 64) + "/s"
+	}
+
+	since := now.Sub(c.created)
+	if beats > 0 {
+		since = now.Sub(last)
+		resp.Details["last_beat"] = last.UTC().Format(time.RFC3339Nano)
+	}
+	resp.Details["since_last_beat"] = latency(since)
+
+	idle := "no beat for " + since.Round(time.Millisecond).String()
+	if beats == 0 {
+		idle = "no beat in " + since.Round(time.Millisecond).String() + " since start"
+	}
+	switch {
+	case since >= c.downAfter:
+		return fail(resp, idle, nil), nil
+	case since >= c.degradedAfter:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = idle
+	case beats == 0:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = "waiting for first beat"
+	default:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = "last beat " + since.Round(time.Millisecond).String() + " ago"
+	}
+	return resp, nil
+}
+
+func (c *HeartbeatChecker) Name() string {
+	return c.name
+}
+
+func (c *HeartbeatChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
@@ -0,0 +1,307 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const defaultMaxBodySize = 1 << 20
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	Min, Max int
+}
+
+// httpAssertion inspects a response and returns an error describing why it
+// is unacceptable.
+type httpAssertion func(resp *http.Response, body []byte, elapsed time.Duration) error
+
+// HTTPChecker checks an HTTP(S) endpoint. A response that fails any assertion
+// is DOWN; one that only fails assertions wrapped in DegradedIf is DEGRADED.
+// Without WithExpectStatus any 2xx status is accepted.
+type HTTPChecker struct {
+	name string
+	url  string
+	must bool
+
+	method  string
+	header  http.Header
+	body    []byte
+	timeout time.Duration
+	maxBody int64
+
+	tls       *tls.Config
+	proxy     *url.URL
+	redirects int
+
+	statusSet bool
+	down      []httpAssertion
+	degraded  []httpAssertion
+
+	client *http.Client
+}
+
+type HTTPOption func(*HTTPChecker)
+
+// WithHTTPTimeout sets the deadline for the whole request including the body.
+// Default: 3s.
+func WithHTTPTimeout(d time.Duration) HTTPOption {
+	return func(c *HTTPChecker) { c.timeout = d }
+}
+
+// WithMethod sets the request method. Default: GET.
+func WithMethod(method string) HTTPOption {
+	return func(c *HTTPChecker) { c.method = method }
+}
+
+// WithHeader adds a request header. A Host header overrides the request host.
+func WithHeader(key, value string) HTTPOption {
+	return func(c *HTTPChecker) { c.header.Add(key, value) }
+}
+
+// WithBody sets the request body. Set Content-Type with WithHeader.
+func WithBody(body []byte) HTTPOption {
+	return func(c *HTTPChecker) { c.body = body }
+}
+
+// WithMaxBodySize limits how much of the response body is read; larger
+// responses are DOWN. Default: 1 MiB.
+func WithMaxBodySize(n int64) HTTPOption {
+	return func(c *HTTPChecker) { c.maxBody = n }
+}
+
+// WithRootCAs sets the CAs used to verify the server certificate.
+func WithRootCAs(pool *x509.CertPool) HTTPOption {
+	return func(c *HTTPChecker) { c.tls.RootCAs = pool }
+}
+
+// WithClientCert presents cert to servers that require client authentication.
+func WithClientCert(cert tls.Certificate) HTTPOption {
+	return func(c *HTTPChecker) { c.tls.Certificates = append(c.tls.Certificates, cert) }
+}
+
+// WithInsecureSkipVerify disables server certificate verification.
+func WithInsecureSkipVerify() HTTPOption {
+	return func(c *HTTPChecker) { c.tls.InsecureSkipVerify = true }
+}
+
+// WithServerName overrides the SNI and the name the certificate is verified against.
+func WithServerName(name string) HTTPOption {
+	return func(c *HTTPChecker) { c.tls.ServerName = name }
+}
+
+// WithRedirects sets how many redirects are followed. Zero checks the
+// redirect response itself. Default: 10.
+func WithRedirects(n int) HTTPOption {
+	return func(c *HTTPChecker) { c.redirects = n }
+}
+
+// WithProxy sends requests through the given proxy. By default the proxy is
+// taken from the environment.
+func WithProxy(proxy *url.URL) HTTPOption {
+	return func(c *HTTPChecker) { c.proxy = proxy }
+}
+
+// WithExpectStatus accepts responses whose status code falls in any of ranges.
+func WithExpectStatus(ranges ...StatusRange) HTTPOption {
+	return func(c *HTTPChecker) {
+		c.statusSet = true
+		c.down = append(c.down, expectStatus(ranges))
+	}
+}
+
+// WithBodyRegex requires the response body to match re.
+func WithBodyRegex(re *regexp.Regexp) HTTPOption {
+	return func(c *HTTPChecker) {
+		c.down = append(c.down, func(_ *http.Response, body []byte, _ time.Duration) error {
+			if !re.Match(body) {
+				return fmt.Errorf("body does not match %q", re.String())
+			}
+			return nil
+		})
+	}
+}
+
+// WithJSONPath requires the JSON response to hold want at path. Paths look
+// like $.status, $.checks[0].name or $["dotted.key"]; strings compare by
+// value and other JSON values by their encoding, e.g. "true" or "42".
+func WithJSONPath(path, want string) HTTPOption {
+	return func(c *HTTPChecker) {
+		c.down = append(c.down, func(_ *http.Response, body []byte, _ time.Duration) error {
+			got, err := jsonPath(body, path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if got != want {
+				return fmt.Errorf("%s = %s, want %s", path, strconv.Quote(got), strconv.Quote(want))
+			}
+			return nil
+		})
+	}
+}
+
+// WithMaxLatency requires the response to arrive within d.
+func WithMaxLatency(d time.Duration) HTTPOption {
+	return func(c *HTTPChecker) {
+		c.down = append(c.down, func(_ *http.Response, _ []byte, elapsed time.Duration) error {
+			if elapsed > d {
+				return fmt.Errorf("latency %s exceeds %s", latency(elapsed), d)
+			}
+			return nil
+		})
+	}
+}
+
+// DegradedIf turns the assertions made by opts (WithExpectStatus, WithBodyRegex,
+// WithJSONPath, WithMaxLatency) into DEGRADED rules: a response failing them
+// is DEGRADED instead of DOWN. Other options passed to DegradedIf are ignored.
+func DegradedIf(opts ...HTTPOption) HTTPOption {
+	return func(c *HTTPChecker) {
+		rules := &HTTPChecker{header: make(http.Header), tls: &tls.Config{}}
+		for _, o := range opts {
+			o(rules)
+		}
+		c.degraded = append(c.degraded, rules.down...)
+	}
+}
+
+func NewHTTPChecker(name, rawURL string, mustOK bool, opts ...HTTPOption) *HTTPChecker {
+	c := &HTTPChecker{
+		name:      name,
+		url:       rawURL,
+		must:      mustOK,
+		method:    http.MethodGet,
+		header:    make(http.Header),
+		timeout:   defaultTimeout,
+		maxBody:   defaultMaxBodySize,
+		tls:       &tls.Config{MinVersion: tls.VersionTLS12},
+		redirects: 10,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+
+	if !c.statusSet {
+		c.down = append([]httpAssertion{expectStatus([]StatusRange{{200, 299}})}, c.down...)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = c.tls
+	if c.proxy != nil {
+		transport.Proxy = http.ProxyURL(c.proxy)
+	}
+
+	c.client = &http.Client{
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if c.redirects == 0 {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > c.redirects {
+				return fmt.Errorf("stopped after %d redirects", c.redirects)
+			}
+			return nil
+		},
+	}
+
+	return c
+}
+
+func (c *HTTPChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, c.method, c.url, bytes.NewReader(c.body))
+	if err != nil {
+		return fail(resp, "invalid request", err), nil
+	}
+	req.Header = c.header.Clone()
+	req.Host = c.header.Get("Host")
+
+	start := time.Now()
+	hr, err := c.client.Do(req)
+	if err != nil {
+		return fail(resp, "request failed", err), nil
+	}
+	defer func() {
+		_ = hr.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(hr.Body, c.maxBody+1))
+	elapsed := time.Since(start)
+	if err != nil {
+		return fail(resp, "read body failed", err), nil
+	}
+
+	resp.Details["status_code"] = strconv.Itoa(hr.StatusCode)
+	resp.Details["latency"] = latency(elapsed)
+	resp.Details["size"] = strconv.Itoa(len(body))
+	if final := hr.Request.URL.String(); final != c.url {
+		resp.Details["final_url"] = final
+	}
+	if hr.TLS != nil {
+		resp.Details["tls_version"] = tls.VersionName(hr.TLS.Version)
+	}
+
+	if int64(len(body)) > c.maxBody {
+		return fail(resp, fmt.Sprintf("response exceeds %d bytes", c.maxBody), nil), nil
+	}
+
+	if err := assertAll(c.down, hr, body, elapsed); err != nil {
+		return fail(resp, "assertion failed", err), nil
+	}
+
+	if err := assertAll(c.degraded, hr, body, elapsed); err != nil {
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = "degraded: " + hr.Status
+		resp.Error = err.Error()
+		return resp, nil
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = hr.Status
+	return resp, nil
+}
+
+func assertAll(assertions []httpAssertion, resp *http.Response, body []byte, elapsed time.Duration) error {
+	var errs []error
+	for _, a := range assertions {
+		if err := a(resp, body, elapsed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func expectStatus(ranges []StatusRange) httpAssertion {
+	return func(resp *http.Response, _ []byte, _ time.Duration) error {
+		for _, r := range ranges {
+			if resp.StatusCode >= r.Min && resp.StatusCode <= r.Max {
+				return nil
+			}
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+func (c *HTTPChecker) Name() string {
+	return c.name
+}
+
+func (c *HTTPChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
@@ -0,0 +1,107 @@
+package checkers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPath evaluates a small JSONPath subset against doc: an optional leading
+// $, then .key, ["key"] and [index] steps. Strings are returned unquoted and
+// any other value in its JSON encoding.
+func jsonPath(doc []byte, path string) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, step := range steps {
+		switch node := v.(type) {
+		case map[string]any:
+			key, ok := step.(string)
+			if !ok {
+				return "", fmt.Errorf("index [%d] applied to an object", step)
+			}
+			if v, ok = node[key]; !ok {
+				return "", fmt.Errorf("key %q not found", key)
+			}
+		case []any:
+			idx, ok := step.(int)
+			if !ok {
+				return "", fmt.Errorf("key %q applied to an array", step)
+			}
+			if idx < 0 || idx >= len(node) {
+				return "", fmt.Errorf("index %d out of range", idx)
+			}
+			v = node[idx]
+		default:
+			return "", fmt.Errorf("cannot step into %v", step)
+		}
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// parseJSONPath splits path into object keys (string) and array indexes (int).
+func parseJSONPath(path string) ([]any, error) {
+	rest := strings.TrimPrefix(path, "$")
+	var steps []any
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in %q", path)
+			}
+			steps = append(steps, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if key, err := strconv.Unquote(inner); err == nil {
+				steps = append(steps, key)
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid step [%s] in %q", inner, path)
+			}
+			steps = append(steps, idx)
+		default:
+			if len(steps) > 0 {
+				return nil, errors.New("unexpected " + strconv.Quote(rest))
+			}
+			// Allow a bare leading key such as "status.db".
+			rest = "." + rest
+		}
+	}
+
+	return steps, nil
+}
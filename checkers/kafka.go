@@ -0,0 +1,360 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	kafkaAPIMetadata    = 3
+	kafkaAPIApiVersions = 18
+	// kafkaMetadataVersion is the first Metadata version that lets the
+	// request disable auto.create.topics.enable; older ones make the broker
+	// create missing topics the check merely asks about.
+	kafkaMetadataVersion = 4
+	kafkaClientID        = "srvmon"
+
+	kafkaUnknownTopicOrPartition = 3
+)
+
+// KafkaChecker asks a broker for its supported API versions and for cluster
+// metadata over the Kafka binary protocol. It reports the controller, the
+// number of brokers and, for the configured topics, under-replicated and
+// offline partitions. Metadata is requested with auto topic creation
+// disabled, so the check never creates topics. Partitions without a leader
+// and transient topic errors such as LEADER_NOT_AVAILABLE make the check
+// DEGRADED; a configured topic the broker does not know makes it DOWN.
+type KafkaChecker struct {
+	name string
+	addr string
+	must bool
+
+	timeout time.Duration
+	topics  []string
+	tls     *tls.Config
+}
+
+type KafkaOption func(*KafkaChecker)
+
+// WithKafkaTimeout sets the deadline for the whole exchange. Default: 3s.
+func WithKafkaTimeout(d time.Duration) KafkaOption {
+	return func(c *KafkaChecker) { c.timeout = d }
+}
+
+// WithKafkaTopics sets the topics whose partitions are inspected.
+func WithKafkaTopics(topics ...string) KafkaOption {
+	return func(c *KafkaChecker) { c.topics = topics }
+}
+
+// WithKafkaTLS connects to the broker over TLS.
+func WithKafkaTLS(cfg *tls.Config) KafkaOption {
+	return func(c *KafkaChecker) { c.tls = cfg }
+}
+
+func NewKafkaChecker(name, addr string, mustOK bool, opts ...KafkaOption) *KafkaChecker {
+	c := &KafkaChecker{name: name, addr: addr, must: mustOK, timeout: defaultTimeout}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// kafkaPartition is the part of the partition metadata the checker uses.
+type kafkaPartition struct {
+	leader   int32
+	replicas int
+	isr      int
+}
+
+type kafkaTopic struct {
+	err        int16
+	name       string
+	partitions []kafkaPartition
+}
+
+func (c *KafkaChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fail(resp, "connection failed", err), nil
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	kc := &kafkaConn{rw: conn}
+
+	body, err := kc.roundTrip(kafkaAPIApiVersions, 0, nil)
+	if err != nil {
+		return fail(resp, "api versions failed", err), nil
+	}
+	minMetadata, maxMetadata, err := parseAPIVersions(body)
+	if err != nil {
+		return fail(resp, "api versions failed", err), nil
+	}
+	if minMetadata > kafkaMetadataVersion || maxMetadata < kafkaMetadataVersion {
+		return fail(resp, fmt.Sprintf("broker supports metadata v%d to v%d, need v%d", minMetadata, maxMetadata, kafkaMetadataVersion), nil), nil
+	}
+
+	req := binary.BigEndian.AppendUint32(nil, uint32(len(c.topics)))
+	for _, topic := range c.topics {
+		req = appendKafkaString(req, topic)
+	}
+	req = append(req, 0) // allow_auto_topic_creation: false
+	body, err = kc.roundTrip(kafkaAPIMetadata, kafkaMetadataVersion, req)
+	if err != nil {
+		return fail(resp, "metadata failed", err), nil
+	}
+	brokers, clusterID, controller, topics, err := parseMetadata(body)
+	if err != nil {
+		return fail(resp, "metadata failed", err), nil
+	}
+	resp.Details["latency"] = latency(time.Since(start))
+	if clusterID != "" {
+		resp.Details["cluster_id"] = clusterID
+	}
+	resp.Details["controller_id"] = strconv.Itoa(int(controller))
+	resp.Details["brokers"] = strconv.Itoa(brokers)
+
+	var (
+		missing, erroneous       []string
+		underReplicated, offline int
+		offlineTopics            []string
+	)
+	for _, t := range topics {
+		switch t.err {
+		case 0:
+		case kafkaUnknownTopicOrPartition:
+			missing = append(missing, t.name)
+			continue
+		default:
+			erroneous = append(erroneous, fmt.Sprintf("%s (error %d)", t.name, t.err))
+			continue
+		}
+		topicOffline := 0
+		for _, p := range t.partitions {
+			if p.leader < 0 {
+				topicOffline++
+			}
+			if p.isr < p.replicas {
+				underReplicated++
+			}
+		}
+		offline += topicOffline
+		resp.Details["topic."+t.name+".partitions"] = strconv.Itoa(len(t.partitions))
+		if topicOffline > 0 {
+			offlineTopics = append(offlineTopics, t.name)
+		}
+	}
+	resp.Details["under_replicated_partitions"] = strconv.Itoa(underReplicated)
+	resp.Details["offline_partitions"] = strconv.Itoa(offline)
+
+	switch {
+	case controller < 0:
+		return fail(resp, "no active controller", nil), nil
+	case len(missing) > 0:
+		return fail(resp, "unknown topics: "+strings.Join(missing, ", "), nil), nil
+	case len(erroneous) > 0:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = "topic metadata unavailable: " + strings.Join(erroneous, ", ")
+	case offline > 0:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = fmt.Sprintf("%d partitions without leader in %s", offline, strings.Join(offlineTopics, ", "))
+	default:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = fmt.Sprintf("%d brokers, controller %d", brokers, controller)
+	}
+	return resp, nil
+}
+
+func (c *KafkaChecker) dial(ctx context.Context) (net.Conn, error) {
+	if c.tls != nil {
+		d := tls.Dialer{Config: c.tls}
+		return d.DialContext(ctx, "tcp", c.addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", c.addr)
+}
+
+// kafkaConn frames requests with a v1 request header and reads responses
+// with a v0 response header.
+type kafkaConn struct {
+	rw          io.ReadWriter
+	correlation int32
+}
+
+func (c *kafkaConn) roundTrip(apiKey, version int16, body []byte) ([]byte, error) {
+	c.correlation++
+
+	req := binary.BigEndian.AppendUint16(nil, uint16(apiKey))
+	req = binary.BigEndian.AppendUint16(req, uint16(version))
+	req = binary.BigEndian.AppendUint32(req, uint32(c.correlation))
+	req = appendKafkaString(req, kafkaClientID)
+	req = append(req, body...)
+	if _, err := c.rw.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(req))), req...)); err != nil {
+		return nil, err
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(c.rw, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n < 4 || n > 16<<20 {
+		return nil, fmt.Errorf("invalid response size %d", n)
+	}
+	resp := make([]byte, n)
+	if _, err := io.ReadFull(c.rw, resp); err != nil {
+		return nil, err
+	}
+	if got := int32(binary.BigEndian.Uint32(resp)); got != c.correlation {
+		return nil, fmt.Errorf("correlation id %d, want %d", got, c.correlation)
+	}
+	return resp[4:], nil
+}
+
+func appendKafkaString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// parseAPIVersions returns the range of Metadata versions the broker supports.
+func parseAPIVersions(body []byte) (minMetadata, maxMetadata int16, err error) {
+	r := &kafkaReader{b: body}
+	if code := r.int16(); code != 0 {
+		return 0, 0, fmt.Errorf("error code %d", code)
+	}
+	minMetadata, maxMetadata = -1, -1
+	for range r.count() {
+		key, minVersion, maxVersion := r.int16(), r.int16(), r.int16()
+		if key == kafkaAPIMetadata {
+			minMetadata, maxMetadata = minVersion, maxVersion
+		}
+	}
+	return minMetadata, maxMetadata, r.err
+}
+
+// parseMetadata decodes a Metadata v4 response.
+func parseMetadata(body []byte) (brokers int, clusterID string, controller int32, topics []kafkaTopic, err error) {
+	r := &kafkaReader{b: body}
+
+	r.int32() // throttle time
+	brokers = r.count()
+	for range brokers {
+		r.int32()  // node id
+		r.string() // host
+		r.int32()  // port
+		r.string() // rack
+	}
+	clusterID = r.string()
+	controller = r.int32()
+
+	for range r.count() {
+		t := kafkaTopic{err: r.int16(), name: r.string()}
+		r.int8() // is internal
+		for range r.count() {
+			r.int16() // error code
+			r.int32() // partition index
+			p := kafkaPartition{leader: r.int32()}
+			p.replicas = r.skipInt32s()
+			p.isr = r.skipInt32s()
+			t.partitions = append(t.partitions, p)
+		}
+		topics = append(topics, t)
+	}
+
+	return brokers, clusterID, controller, topics, r.err
+}
+
+// kafkaReader decodes big-endian protocol fields. After the first short read
+// every further read returns zero and err stays set.
+type kafkaReader struct {
+	b   []byte
+	err error
+}
+
+func (r *kafkaReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.b) < n {
+		r.err = errors.New("truncated response")
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *kafkaReader) int8() int8 {
+	if b := r.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (r *kafkaReader) int16() int16 {
+	if b := r.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int32() int32 {
+	if b := r.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+// count reads an array length; null arrays count as empty. Lengths the
+// remaining bytes cannot hold are rejected before anything loops over them.
+func (r *kafkaReader) count() int {
+	n := int(r.int32())
+	if n > len(r.b) {
+		r.err = errors.New("truncated response")
+		return 0
+	}
+	return max(n, 0)
+}
+
+// string reads a nullable string.
+func (r *kafkaReader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.take(int(n)))
+}
+
+// skipInt32s skips an int32 array and returns its length.
+func (r *kafkaReader) skipInt32s() int {
+	n := r.count()
+	r.take(4 * n)
+	return n
+}
+
+func (c *KafkaChecker) Name() string {
+	return c.name
+}
+
+func (c *KafkaChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
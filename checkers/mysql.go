@@ -0,0 +1,378 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	mysqlClientLongPassword = 0x00000001
+	mysqlClientProtocol41   = 0x00000200
+	mysqlClientSSL          = 0x00000800
+	mysqlClientTransactions = 0x00002000
+	mysqlClientSecureConn   = 0x00008000
+	mysqlClientPluginAuth   = 0x00080000
+
+	mysqlAccessDenied = 1045
+	mysqlComQuit      = 0x01
+)
+
+// MySQLChecker logs in to a MySQL or MariaDB server at the wire-protocol
+// level (mysql_native_password or caching_sha2_password) and ends the
+// session with COM_QUIT, so probes never count towards max_connect_errors.
+// Without a check user the login is expected to be denied. It reports the
+// server version and handshake details; the handshake does not reveal
+// read-only state.
+type MySQLChecker struct {
+	name string
+	addr string
+	must bool
+
+	timeout     time.Duration
+	user        string
+	password    string
+	retrieveKey bool
+}
+
+type MySQLOption func(*MySQLChecker)
+
+// WithMySQLTimeout sets the deadline for the whole session. Default: 3s.
+func WithMySQLTimeout(d time.Duration) MySQLOption {
+	return func(c *MySQLChecker) { c.timeout = d }
+}
+
+// WithMySQLUser sets the account to log in as; the login must then succeed.
+// Default: an anonymous login that the server is expected to deny.
+func WithMySQLUser(user, password string) MySQLOption {
+	return func(c *MySQLChecker) {
+		c.user = user
+		c.password = password
+	}
+}
+
+// WithMySQLPublicKeyRetrieval lets a caching_sha2_password login whose
+// password the server has not cached yet fetch the server's RSA public key
+// to encrypt the password. The connection is not encrypted, so an attacker
+// in the middle can swap in their own key and read the password; only enable
+// it on trusted networks. Without it such a login fails.
+func WithMySQLPublicKeyRetrieval() MySQLOption {
+	return func(c *MySQLChecker) { c.retrieveKey = true }
+}
+
+func NewMySQLChecker(name, addr string, mustOK bool, opts ...MySQLOption) *MySQLChecker {
+	c := &MySQLChecker{name: name, addr: addr, must: mustOK, timeout: defaultTimeout}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *MySQLChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return fail(resp, "connection failed", err), nil
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	mc := &mysqlConn{rw: conn}
+	payload, err := mc.read()
+	if err != nil {
+		return fail(resp, "read handshake failed", err), nil
+	}
+	if len(payload) > 0 && payload[0] == 0xff {
+		return fail(resp, "server refused connection", mysqlError(payload)), nil
+	}
+
+	hs, err := parseMySQLHandshake(payload, resp.Details)
+	if err != nil {
+		return fail(resp, "invalid handshake", err), nil
+	}
+
+	err = mc.login(c.user, c.password, hs, c.retrieveKey)
+	resp.Details["latency"] = latency(time.Since(start))
+	var denied *mysqlServerError
+	switch {
+	case c.user == "" && errors.As(err, &denied) && denied.code == mysqlAccessDenied:
+		resp.Details["login"] = "denied"
+	case err != nil:
+		return fail(resp, "login failed", err), nil
+	default:
+		resp.Details["login"] = "ok"
+		// End the session politely; the result does not depend on it.
+		mc.seq = 0
+		_ = mc.write([]byte{mysqlComQuit})
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = "accepting connections"
+	return resp, nil
+}
+
+// mysqlHandshake is what the login needs from the server's greeting.
+type mysqlHandshake struct {
+	salt   []byte
+	plugin string
+}
+
+// parseMySQLHandshake reads a protocol version 10 handshake into details.
+func parseMySQLHandshake(p []byte, details map[string]string) (*mysqlHandshake, error) {
+	if len(p) < 1 || p[0] != 10 {
+		return nil, errors.New("unsupported protocol version")
+	}
+	details["protocol_version"] = "10"
+	p = p[1:]
+
+	end := bytes.IndexByte(p, 0)
+	if end < 0 {
+		return nil, errors.New("truncated server version")
+	}
+	details["server_version"] = string(p[:end])
+	p = p[end+1:]
+
+	// connection id (4), auth data part 1 (8), filler (1), capabilities low (2)
+	if len(p) < 15 {
+		return nil, errors.New("truncated handshake")
+	}
+	details["connection_id"] = strconv.FormatUint(uint64(binary.LittleEndian.Uint32(p)), 10)
+	hs := &mysqlHandshake{salt: bytes.Clone(p[4:12]), plugin: "mysql_native_password"}
+	caps := uint32(binary.LittleEndian.Uint16(p[13:]))
+	p = p[15:]
+
+	// charset (1), status flags (2), capabilities high (2), auth data length (1), reserved (10)
+	if len(p) >= 16 {
+		caps |= uint32(binary.LittleEndian.Uint16(p[3:])) << 16
+		authLen := int(p[5])
+		p = p[16:]
+
+		// auth data part 2, then the auth plugin name
+		skip := max(13, authLen-8)
+		if len(p) >= skip {
+			hs.salt = append(hs.salt, bytes.TrimRight(p[:skip], "\x00")...)
+		}
+		if len(p) > skip {
+			name, _, _ := bytes.Cut(p[skip:], []byte{0})
+			hs.plugin = string(name)
+			details["auth_plugin"] = hs.plugin
+		}
+	}
+	details["tls_supported"] = strconv.FormatBool(caps&mysqlClientSSL != 0)
+
+	if caps&mysqlClientProtocol41 == 0 {
+		return nil, errors.New("server does not support protocol 4.1")
+	}
+	return hs, nil
+}
+
+// mysqlConn reads and writes MySQL packets, tracking the sequence id.
+type mysqlConn struct {
+	rw  io.ReadWriter
+	seq byte
+}
+
+func (c *mysqlConn) read() ([]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(c.rw, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := int(hdr[0]) | int(hdr[1])<<8 | int(hdr[2])<<16
+	if n > 1<<20 {
+		return nil, fmt.Errorf("invalid packet length %d", n)
+	}
+	c.seq = hdr[3] + 1
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (c *mysqlConn) write(payload []byte) error {
+	hdr := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), c.seq}
+	c.seq++
+	_, err := c.rw.Write(append(hdr, payload...))
+	return err
+}
+
+// login sends the HandshakeResponse and follows auth switches and
+// caching_sha2_password exchanges until the server answers OK or ERR. A full
+// caching_sha2_password login requests the server's public key only if
+// retrieveKey is set.
+func (c *mysqlConn) login(user, password string, hs *mysqlHandshake, retrieveKey bool) error {
+	plugin, salt := hs.plugin, hs.salt
+	auth, err := mysqlScramble(plugin, password, salt)
+	if err != nil {
+		return err
+	}
+
+	caps := uint32(mysqlClientLongPassword | mysqlClientProtocol41 | mysqlClientTransactions |
+		mysqlClientSecureConn | mysqlClientPluginAuth)
+	msg := binary.LittleEndian.AppendUint32(nil, caps)
+	msg = binary.LittleEndian.AppendUint32(msg, 1<<24) // max packet size
+	msg = append(msg, 0x21)                            // utf8_general_ci
+	msg = append(msg, make([]byte, 23)...)
+	msg = append(msg, user...)
+	msg = append(msg, 0, byte(len(auth)))
+	msg = append(msg, auth...)
+	msg = append(msg, plugin...)
+	msg = append(msg, 0)
+	if err := c.write(msg); err != nil {
+		return err
+	}
+
+	for range 4 {
+		p, err := c.read()
+		if err != nil {
+			return err
+		}
+		if len(p) == 0 {
+			return errors.New("empty packet")
+		}
+
+		switch p[0] {
+		case 0x00: // OK
+			return nil
+		case 0xff:
+			return mysqlError(p)
+		case 0xfe: // AuthSwitchRequest
+			name, data, _ := bytes.Cut(p[1:], []byte{0})
+			plugin, salt = string(name), bytes.TrimRight(data, "\x00")
+			if auth, err = mysqlScramble(plugin, password, salt); err != nil {
+				return err
+			}
+			err = c.write(auth)
+		case 0x01: // AuthMoreData
+			switch {
+			case plugin != "caching_sha2_password" || len(p) < 2:
+				return fmt.Errorf("unexpected auth data for %s", plugin)
+			case p[1] == 3: // fast auth succeeded, OK follows
+				continue
+			case p[1] == 4 && !retrieveKey:
+				return errors.New("full caching_sha2_password authentication needs the server's public key, see WithMySQLPublicKeyRetrieval")
+			case p[1] == 4: // full auth: ask for the server's RSA key
+				err = c.write([]byte{0x02})
+			default: // the public key
+				var enc []byte
+				if enc, err = mysqlEncryptPassword(p[1:], password, salt); err == nil {
+					err = c.write(enc)
+				}
+			}
+		default:
+			return fmt.Errorf("unexpected packet 0x%02x", p[0])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return errors.New("too many authentication rounds")
+}
+
+// mysqlScramble computes the auth response of plugin for password.
+func mysqlScramble(plugin, password string, salt []byte) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	switch plugin {
+	case "mysql_native_password":
+		// SHA1(password) XOR SHA1(salt + SHA1(SHA1(password)))
+		h1 := sha1.Sum([]byte(password))
+		h2 := sha1.Sum(h1[:])
+		h3 := sha1.Sum(append(bytes.Clone(salt), h2[:]...))
+		return xorBytes(h1[:], h3[:]), nil
+	case "caching_sha2_password":
+		// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + salt)
+		d1 := sha256.Sum256([]byte(password))
+		d2 := sha256.Sum256(d1[:])
+		d3 := sha256.Sum256(append(d2[:], salt...))
+		return xorBytes(d1[:], d3[:]), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth plugin %q", plugin)
+	}
+}
+
+// mysqlEncryptPassword encrypts the NUL-terminated password, XORed with the
+// salt, with the server's PEM-encoded RSA key.
+func mysqlEncryptPassword(key []byte, password string, salt []byte) ([]byte, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("invalid server public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok || len(salt) == 0 {
+		return nil, errors.New("invalid server public key")
+	}
+	plain := append([]byte(password), 0)
+	for i := range plain {
+		plain[i] ^= salt[i%len(salt)]
+	}
+	return rsa.EncryptOAEP(sha1.New(), rand.Reader, rsaPub, plain, nil)
+}
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+// mysqlServerError is an ERR packet.
+type mysqlServerError struct {
+	code uint16
+	msg  string
+}
+
+func (e *mysqlServerError) Error() string {
+	return fmt.Sprintf("error %d: %s", e.code, e.msg)
+}
+
+// mysqlError formats an ERR packet.
+func mysqlError(p []byte) error {
+	if len(p) < 3 {
+		return errors.New("malformed error packet")
+	}
+	code := binary.LittleEndian.Uint16(p[1:])
+	msg := p[3:]
+	if len(msg) > 6 && msg[0] == '#' {
+		msg = msg[6:] // SQL state marker and state
+	}
+	return &mysqlServerError{code: code, msg: string(msg)}
+}
+
+func (c *MySQLChecker) Name() string {
+	return c.name
+}
+
+func (c *MySQLChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
@@ -0,0 +1,404 @@
+package checkers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	pgProtocolVersion = 196608 // 3.0
+	pgSSLRequestCode  = 80877103
+)
+
+// PostgresChecker opens a PostgreSQL session at the wire-protocol level:
+// optional SSLRequest, startup message and authentication (trust, cleartext,
+// MD5 or SCRAM-SHA-256). It reports the server version and, on servers that
+// announce them (PostgreSQL 14+), hot standby and read-only state.
+type PostgresChecker struct {
+	name string
+	addr string
+	must bool
+
+	timeout  time.Duration
+	user     string
+	password string
+	database string
+	tls      *tls.Config
+}
+
+type PostgresOption func(*PostgresChecker)
+
+// WithPostgresTimeout sets the deadline for the whole session. Default: 3s.
+func WithPostgresTimeout(d time.Duration) PostgresOption {
+	return func(c *PostgresChecker) { c.timeout = d }
+}
+
+// WithPostgresUser sets the role to log in as. Default: postgres without a password.
+func WithPostgresUser(user, password string) PostgresOption {
+	return func(c *PostgresChecker) {
+		c.user = user
+		c.password = password
+	}
+}
+
+// WithPostgresDatabase sets the database to connect to. Default: the user name.
+func WithPostgresDatabase(name string) PostgresOption {
+	return func(c *PostgresChecker) { c.database = name }
+}
+
+// WithPostgresTLS requires TLS, negotiated with an SSLRequest.
+func WithPostgresTLS(cfg *tls.Config) PostgresOption {
+	return func(c *PostgresChecker) { c.tls = cfg }
+}
+
+func NewPostgresChecker(name, addr string, mustOK bool, opts ...PostgresOption) *PostgresChecker {
+	c := &PostgresChecker{name: name, addr: addr, must: mustOK, timeout: defaultTimeout, user: "postgres"}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *PostgresChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return fail(resp, "connection failed", err), nil
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if c.tls != nil {
+		tc, err := c.startTLS(conn)
+		if err != nil {
+			return fail(resp, "tls negotiation failed", err), nil
+		}
+		resp.Details["tls_version"] = tls.VersionName(tc.ConnectionState().Version)
+		conn = tc
+	}
+
+	pc := &pgConn{w: conn, r: bufio.NewReader(conn)}
+	if err := pc.startup(c.user, c.database); err != nil {
+		return fail(resp, "startup failed", err), nil
+	}
+
+	params, err := pc.authenticate(c.user, c.password, resp)
+	if err != nil {
+		return fail(resp, "login failed", err), nil
+	}
+	resp.Details["latency"] = latency(time.Since(start))
+
+	// Terminate the session politely; the result does not depend on it.
+	_ = pc.send('X', nil)
+
+	resp.Details["server_version"] = params["server_version"]
+	if v, ok := params["in_hot_standby"]; ok {
+		resp.Details["in_recovery"] = strconv.FormatBool(v == "on")
+	}
+	if v, ok := params["default_transaction_read_only"]; ok {
+		resp.Details["read_only"] = strconv.FormatBool(v == "on")
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = "accepting connections"
+	if resp.Details["in_recovery"] == "true" {
+		resp.Message = "accepting connections (in recovery)"
+	}
+	return resp, nil
+}
+
+// startTLS sends an SSLRequest and upgrades conn when the server agrees.
+func (c *PostgresChecker) startTLS(conn net.Conn) (*tls.Conn, error) {
+	req := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, 8), pgSSLRequestCode)
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	answer := make([]byte, 1)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return nil, err
+	}
+	if answer[0] != 'S' {
+		return nil, errors.New("server does not accept TLS")
+	}
+
+	cfg := c.tls.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName, _, _ = net.SplitHostPort(c.addr)
+	}
+	tc := tls.Client(conn, cfg)
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// pgConn reads and writes PostgreSQL protocol messages.
+type pgConn struct {
+	w io.Writer
+	r *bufio.Reader
+}
+
+func (c *pgConn) startup(user, database string) error {
+	msg := binary.BigEndian.AppendUint32(nil, pgProtocolVersion)
+	for _, kv := range [][2]string{{"user", user}, {"database", database}, {"application_name", "srvmon"}} {
+		if kv[1] == "" {
+			continue
+		}
+		msg = append(msg, kv[0]...)
+		msg = append(msg, 0)
+		msg = append(msg, kv[1]...)
+		msg = append(msg, 0)
+	}
+	msg = append(msg, 0)
+
+	_, err := c.w.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(msg)+4)), msg...))
+	return err
+}
+
+// send writes a typed message.
+func (c *pgConn) send(typ byte, body []byte) error {
+	msg := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	_, err := c.w.Write(append(msg, body...))
+	return err
+}
+
+// receive reads one typed message. ErrorResponse messages are returned as errors.
+func (c *pgConn) receive() (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n < 4 || n > 1<<20 {
+		return 0, nil, fmt.Errorf("invalid message length %d", n)
+	}
+	body := make([]byte, n-4)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	if hdr[0] == 'E' {
+		return 0, nil, pgError(body)
+	}
+	return hdr[0], body, nil
+}
+
+// authenticate answers the server's authentication requests and collects
+// the parameters it reports until it is ready for queries.
+func (c *pgConn) authenticate(user, password string, resp *pb.CheckResult) (map[string]string, error) {
+	var scram *scramClient
+	params := make(map[string]string)
+
+	for {
+		typ, body, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case 'R':
+			if len(body) < 4 {
+				return nil, errors.New("short authentication message")
+			}
+			code, data := binary.BigEndian.Uint32(body), body[4:]
+
+			switch code {
+			case 0: // AuthenticationOk
+				if resp.Details["auth"] == "" {
+					resp.Details["auth"] = "trust"
+				}
+			case 3: // AuthenticationCleartextPassword
+				resp.Details["auth"] = "password"
+				if password == "" {
+					return nil, errors.New("server requires a password")
+				}
+				err = c.send('p', append([]byte(password), 0))
+			case 5: // AuthenticationMD5Password
+				resp.Details["auth"] = "md5"
+				if password == "" {
+					return nil, errors.New("server requires a password")
+				}
+				err = c.send('p', append([]byte(md5Password(user, password, data)), 0))
+			case 10: // AuthenticationSASL
+				resp.Details["auth"] = "scram-sha-256"
+				if !bytes.Contains(data, []byte("SCRAM-SHA-256\x00")) {
+					return nil, errors.New("no supported SASL mechanism")
+				}
+				if password == "" {
+					return nil, errors.New("server requires a password")
+				}
+				scram = newScramClient(password)
+				first := scram.first()
+				msg := append([]byte("SCRAM-SHA-256\x00"), binary.BigEndian.AppendUint32(nil, uint32(len(first)))...)
+				err = c.send('p', append(msg, first...))
+			case 11: // AuthenticationSASLContinue
+				if scram == nil {
+					return nil, errors.New("unexpected SASL continue")
+				}
+				var final string
+				if final, err = scram.final(string(data)); err == nil {
+					err = c.send('p', []byte(final))
+				}
+			case 12: // AuthenticationSASLFinal
+				if scram == nil {
+					return nil, errors.New("unexpected SASL final")
+				}
+				err = scram.verify(string(data))
+			default:
+				return nil, fmt.Errorf("unsupported authentication method %d", code)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+		case 'S': // ParameterStatus
+			if k, v, ok := strings.Cut(strings.TrimSuffix(string(body), "\x00"), "\x00"); ok {
+				params[k] = v
+			}
+
+		case 'Z': // ReadyForQuery
+			return params, nil
+		}
+	}
+}
+
+// pgError formats the fields of an ErrorResponse.
+func pgError(body []byte) error {
+	fields := make(map[byte]string)
+	for _, f := range bytes.Split(body, []byte{0}) {
+		if len(f) > 1 {
+			fields[f[0]] = string(f[1:])
+		}
+	}
+	return fmt.Errorf("%s %s: %s", fields['S'], fields['C'], fields['M'])
+}
+
+func md5Password(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+	return "md5" + hex.EncodeToString(outer[:])
+}
+
+// maxScramIterations bounds the PBKDF2 iteration count a server may ask for.
+// PostgreSQL defaults to 4096; far larger counts only burn CPU.
+const maxScramIterations = 1 << 20
+
+// scramClient implements the client side of SCRAM-SHA-256 (RFC 7677)
+// without channel binding.
+type scramClient struct {
+	password    string
+	nonce       string
+	firstBare   string
+	authMessage string
+	salted      []byte
+}
+
+func newScramClient(password string) *scramClient {
+	b := make([]byte, 18)
+	_, _ = rand.Read(b)
+	return &scramClient{password: password, nonce: base64.RawStdEncoding.EncodeToString(b)}
+}
+
+func (s *scramClient) first() string {
+	// PostgreSQL takes the user from the startup message.
+	s.firstBare = "n=,r=" + s.nonce
+	return "n,," + s.firstBare
+}
+
+func (s *scramClient) final(serverFirst string) (string, error) {
+	attrs := scramAttrs(serverFirst)
+	nonce, salt64, iter := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, s.nonce) {
+		return "", errors.New("server nonce does not extend client nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return "", fmt.Errorf("invalid salt: %w", err)
+	}
+	iterations, err := strconv.Atoi(iter)
+	if err != nil || iterations < 1 || iterations > maxScramIterations {
+		return "", fmt.Errorf("invalid iteration count %q", iter)
+	}
+
+	s.salted, err = pbkdf2.Key(sha256.New, s.password, salt, iterations, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+
+	withoutProof := "c=biws,r=" + nonce
+	s.authMessage = s.firstBare + "," + serverFirst + "," + withoutProof
+
+	clientKey := hmacSHA256(s.salted, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	signature := hmacSHA256(storedKey[:], s.authMessage)
+	for i := range clientKey {
+		clientKey[i] ^= signature[i]
+	}
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(clientKey), nil
+}
+
+func (s *scramClient) verify(serverFinal string) error {
+	attrs := scramAttrs(serverFinal)
+	if e, ok := attrs["e"]; ok {
+		return errors.New("scram: " + e)
+	}
+	want := hmacSHA256(hmacSHA256(s.salted, "Server Key"), s.authMessage)
+	got, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(got, want) {
+		return errors.New("scram: invalid server signature")
+	}
+	return nil
+}
+
+func scramAttrs(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, kv := range strings.Split(msg, ",") {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+func (c *PostgresChecker) Name() string {
+	return c.name
+}
+
+func (c *PostgresChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
@@ -0,0 +1,269 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// PressureChecker reads Linux pressure stall information from
+// /proc/pressure/{cpu,memory,io} and CPU throttling from the cpu.stat of the
+// process's own cgroup v2, as named in /proc/self/cgroup. Over a window of
+// at least WithPressureWindow it derives the share of wall time tasks were
+// stalled and the share of CFS periods that were throttled; until the first
+// window has passed pressure falls back to the kernel's avg10. Values over a
+// threshold make the check DEGRADED, never DOWN, so readiness can shed load
+// without liveness restarting the process. Missing files (kernels without
+// PSI, cgroups without a CPU quota) are skipped.
+type PressureChecker struct {
+	name string
+	must bool
+
+	root       string
+	pressure   map[string]float64
+	throttling float64
+	window     time.Duration
+
+	// Rates are taken against base, which is always at least one window
+	// older than last; last becomes the new base once it is a window old.
+	mu   sync.Mutex
+	base *pressureSample
+	last *pressureSample
+}
+
+type PressureOption func(*PressureChecker)
+
+// WithPressureRoot reads proc/pressure, proc/self/cgroup and sys/fs/cgroup
+// below root instead of /, e.g. a directory of fixture files. Default: /.
+func WithPressureRoot(root string) PressureOption {
+	return func(c *PressureChecker) { c.root = root }
+}
+
+// WithMaxPressure sets the share of time (0-100) some tasks may be stalled on
+// cpu, memory and io. Zero disables a threshold. Default: 50, 20 and 50.
+func WithMaxPressure(cpu, memory, io float64) PressureOption {
+	return func(c *PressureChecker) {
+		c.pressure = map[string]float64{"cpu": cpu, "memory": memory, "io": io}
+	}
+}
+
+// WithMaxThrottling sets the share (0-1) of CFS periods that may be
+// throttled. Zero disables the threshold. Default: 0.25.
+func WithMaxThrottling(ratio float64) PressureOption {
+	return func(c *PressureChecker) { c.throttling = ratio }
+}
+
+// WithPressureWindow sets the shortest span rates are computed over, so that
+// checks moments apart do not report noise. Default: 10s.
+func WithPressureWindow(d time.Duration) PressureOption {
+	return func(c *PressureChecker) { c.window = d }
+}
+
+func NewPressureChecker(name string, mustOK bool, opts ...PressureOption) *PressureChecker {
+	c := &PressureChecker{
+		name:       name,
+		must:       mustOK,
+		root:       "/",
+		pressure:   map[string]float64{"cpu": 50, "memory": 20, "io": 50},
+		throttling: 0.25,
+		window:     10 * time.Second,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// pressureSample is one reading of the stall and throttling counters.
+type pressureSample struct {
+	at    time.Time
+	psi   map[string]psiLine // by "cpu.some", "memory.full", ...
+	cpu   map[string]uint64  // cpu.stat fields
+	hasCG bool
+}
+
+type psiLine struct {
+	avg10 float64
+	total uint64 // microseconds stalled
+}
+
+func (c *PressureChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	cur, err := c.sample()
+	if err != nil {
+		return fail(resp, "no pressure data", err), nil
+	}
+	prev := c.baseline(cur)
+
+	var v verdict
+	for _, res := range pressureResources {
+		for _, kind := range []string{"some", "full"} {
+			key := res + "." + kind
+			line, ok := cur.psi[key]
+			if !ok {
+				continue
+			}
+			resp.Details[key+"_avg10"] = formatPercent(line.avg10)
+
+			value := line.avg10
+			if prev != nil {
+				if before, ok := prev.psi[key]; ok && line.total >= before.total {
+					elapsed := cur.at.Sub(prev.at).Microseconds()
+					value = percent(line.total-before.total, uint64(max(elapsed, 1)))
+					resp.Details[key+"_rate"] = formatPercent(value)
+				}
+			}
+			if kind == "some" {
+				v.judge(res+" pressure", value, [2]float64{c.pressure[res], 0}, formatPercent(value), formatPercent)
+			}
+		}
+	}
+
+	if cur.hasCG {
+		resp.Details["nr_throttled"] = strconv.FormatUint(cur.cpu["nr_throttled"], 10)
+		resp.Details["throttled_usec"] = strconv.FormatUint(cur.cpu["throttled_usec"], 10)
+		if prev != nil && prev.hasCG {
+			if cur.cpu["nr_periods"] > prev.cpu["nr_periods"] && cur.cpu["nr_throttled"] >= prev.cpu["nr_throttled"] {
+				periods := cur.cpu["nr_periods"] - prev.cpu["nr_periods"]
+				throttled := cur.cpu["nr_throttled"] - prev.cpu["nr_throttled"]
+				ratio := float64(throttled) / float64(periods)
+				resp.Details["throttled_ratio"] = formatRatio(ratio)
+				v.judge("throttled periods", ratio, [2]float64{c.throttling, 0}, formatRatio(ratio), formatRatio)
+			}
+			if elapsed := cur.at.Sub(prev.at); elapsed > 0 && cur.cpu["throttled_usec"] >= prev.cpu["throttled_usec"] {
+				usec := cur.cpu["throttled_usec"] - prev.cpu["throttled_usec"]
+				perSec := time.Duration(float64(usec) * float64(time.Microsecond) / elapsed.Seconds())
+				resp.Details["throttled_per_second"] = latency(perSec)
+			}
+		}
+	}
+
+	return v.result(resp, "no pressure"), nil
+}
+
+// baseline returns the sample to compute rates against, or nil while less
+// than a window has passed since the first check.
+func (c *PressureChecker) baseline(cur *pressureSample) *pressureSample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.last == nil:
+		c.last = cur
+	case cur.at.Sub(c.last.at) >= c.window:
+		c.base, c.last = c.last, cur
+	}
+	return c.base
+}
+
+// sample reads every available source. It fails only when none is readable.
+func (c *PressureChecker) sample() (*pressureSample, error) {
+	s := &pressureSample{at: time.Now(), psi: make(map[string]psiLine)}
+	var errs []error
+
+	for _, res := range pressureResources {
+		if err := readPSI(filepath.Join(c.root, "proc", "pressure", res), res, s.psi); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	cpu, err := c.readCPUStat()
+	if err != nil {
+		errs = append(errs, err)
+	} else if cpu["nr_periods"] > 0 {
+		s.cpu, s.hasCG = cpu, true
+	}
+
+	if len(s.psi) == 0 && !s.hasCG {
+		return nil, errors.Join(errs...)
+	}
+	return s, nil
+}
+
+// readCPUStat reads cpu.stat of the process's own cgroup, falling back to
+// the cgroup mounted at sys/fs/cgroup, which is the process's own inside a
+// private cgroup namespace.
+func (c *PressureChecker) readCPUStat() (map[string]uint64, error) {
+	if own := ownCgroup(c.root); own != "" {
+		if cpu, err := readFlatKeyed(filepath.Join(own, "cpu.stat")); err == nil {
+			return cpu, nil
+		}
+	}
+	return readFlatKeyed(filepath.Join(c.root, "sys", "fs", "cgroup", "cpu.stat"))
+}
+
+// readPSI parses lines such as
+// "some avg10=1.23 avg60=0.50 avg300=0.10 total=123456".
+func readPSI(path, res string, into map[string]psiLine) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var line psiLine
+		for _, kv := range fields[1:] {
+			k, v, _ := strings.Cut(kv, "=")
+			switch k {
+			case "avg10":
+				if line.avg10, err = strconv.ParseFloat(v, 64); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			case "total":
+				if line.total, err = strconv.ParseUint(v, 10, 64); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+		}
+		into[res+"."+fields[0]] = line
+	}
+	return sc.Err()
+}
+
+// readFlatKeyed parses a cgroup "key value" file such as cpu.stat.
+func readFlatKeyed(path string) (map[string]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]uint64)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		k, v, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, k, err)
+		}
+		out[k] = n
+	}
+	return out, nil
+}
+
+func (c *PressureChecker) Name() string {
+	return c.name
+}
+
+func (c *PressureChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
@@ -0,0 +1,284 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// RedisChecker checks a Redis server by speaking RESP directly: optional
+// AUTH and SELECT, then PING, and optionally INFO replication.
+type RedisChecker struct {
+	name string
+	addr string
+	must bool
+
+	timeout     time.Duration
+	username    string
+	password    string
+	db          int
+	tls         *tls.Config
+	replication bool
+}
+
+type RedisOption func(*RedisChecker)
+
+// WithRedisTimeout sets the deadline for the whole exchange. Default: 3s.
+func WithRedisTimeout(d time.Duration) RedisOption {
+	return func(c *RedisChecker) { c.timeout = d }
+}
+
+// WithRedisAuth authenticates with AUTH, also when the password is empty, as
+// for ACL users created with nopass. An empty username uses the
+// single-password form understood by servers without ACLs.
+func WithRedisAuth(username, password string) RedisOption {
+	return func(c *RedisChecker) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithRedisDB selects the logical database n before PING.
+func WithRedisDB(n int) RedisOption {
+	return func(c *RedisChecker) { c.db = n }
+}
+
+// WithRedisTLS connects over TLS with cfg.
+func WithRedisTLS(cfg *tls.Config) RedisOption {
+	return func(c *RedisChecker) { c.tls = cfg }
+}
+
+// WithReplicationInfo runs INFO replication and reports the role, connected
+// replicas and replication lag. A replica whose link to the master is down
+// is DEGRADED.
+func WithReplicationInfo() RedisOption {
+	return func(c *RedisChecker) { c.replication = true }
+}
+
+func NewRedisChecker(name, addr string, mustOK bool, opts ...RedisOption) *RedisChecker {
+	c := &RedisChecker{name: name, addr: addr, must: mustOK, timeout: defaultTimeout}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *RedisChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fail(resp, "connection failed", err), nil
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	rc := &respConn{w: conn, r: bufio.NewReader(conn)}
+
+	if c.username != "" || c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := rc.do(args...); err != nil {
+			return fail(resp, "auth failed", err), nil
+		}
+	}
+
+	if c.db != 0 {
+		if _, err := rc.do("SELECT", strconv.Itoa(c.db)); err != nil {
+			return fail(resp, "select failed", err), nil
+		}
+	}
+
+	start := time.Now()
+	pong, err := rc.do("PING")
+	if err != nil {
+		return fail(resp, "ping failed", err), nil
+	}
+	resp.Details["latency"] = latency(time.Since(start))
+	if pong != "PONG" {
+		return fail(resp, "unexpected ping reply "+strconv.Quote(pong), nil), nil
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = "PONG"
+
+	if c.replication {
+		info, err := rc.do("INFO", "replication")
+		if err != nil {
+			return fail(resp, "info failed", err), nil
+		}
+		c.replicationDetails(resp, parseInfo(info))
+	}
+
+	return resp, nil
+}
+
+func (c *RedisChecker) dial(ctx context.Context) (net.Conn, error) {
+	if c.tls != nil {
+		d := tls.Dialer{Config: c.tls}
+		return d.DialContext(ctx, "tcp", c.addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", c.addr)
+}
+
+// replicationDetails reports the replication state and degrades replicas
+// that lost their master.
+func (c *RedisChecker) replicationDetails(resp *pb.CheckResult, info map[string]string) {
+	role := info["role"]
+	resp.Details["role"] = role
+
+	switch role {
+	case "master":
+		resp.Details["connected_replicas"] = info["connected_slaves"]
+		offset, _ := strconv.ParseInt(info["master_repl_offset"], 10, 64)
+		for i := 0; ; i++ {
+			replica, ok := info["slave"+strconv.Itoa(i)]
+			if !ok {
+				break
+			}
+			fields := parseInfoFields(replica)
+			addr := net.JoinHostPort(fields["ip"], fields["port"])
+			if replicaOffset, err := strconv.ParseInt(fields["offset"], 10, 64); err == nil {
+				resp.Details["lag."+addr] = strconv.FormatInt(offset-replicaOffset, 10)
+			}
+		}
+
+	case "slave":
+		resp.Details["master"] = net.JoinHostPort(info["master_host"], info["master_port"])
+		resp.Details["master_link_status"] = info["master_link_status"]
+		if s := info["master_last_io_seconds_ago"]; s != "" && s != "-1" {
+			resp.Details["lag"] = s + "s"
+		}
+		if info["master_link_status"] != "up" {
+			resp.Status = pb.Status_STATUS_DEGRADED
+			resp.Message = "replica link to master " + info["master_link_status"]
+		}
+	}
+}
+
+// parseInfo parses the key:value lines of an INFO reply.
+func parseInfo(s string) map[string]string {
+	info := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			info[k] = v
+		}
+	}
+	return info
+}
+
+// parseInfoFields parses values like "ip=10.0.0.2,port=6379,offset=42".
+func parseInfoFields(s string) map[string]string {
+	fields := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+const (
+	// maxRESPLength bounds the bulk string and array lengths a reply may claim.
+	maxRESPLength = 1 << 20
+	// maxRESPDepth bounds how deeply array replies may nest.
+	maxRESPDepth = 8
+)
+
+// respConn sends RESP commands and reads their replies.
+type respConn struct {
+	w io.Writer
+	r *bufio.Reader
+}
+
+// do sends a command and returns its reply as a string. Error replies are
+// returned as errors; array replies are read and discarded.
+func (c *respConn) do(args ...string) (string, error) {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		_, _ = fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.w, b.String()); err != nil {
+		return "", err
+	}
+	return c.read(0)
+}
+
+// read reads one reply nested depth arrays deep.
+func (c *respConn) read(depth int) (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", errors.New(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 || n > maxRESPLength {
+			return "", fmt.Errorf("invalid bulk length %q", line[1:])
+		}
+		if n == -1 {
+			return "", nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 || n > maxRESPLength {
+			return "", fmt.Errorf("invalid array length %q", line[1:])
+		}
+		if depth >= maxRESPDepth {
+			return "", errors.New("array reply nested too deeply")
+		}
+		for range max(n, 0) {
+			if _, err := c.read(depth + 1); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected reply %q", line)
+	}
+}
+
+func (c *RedisChecker) Name() string {
+	return c.name
+}
+
+func (c *RedisChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
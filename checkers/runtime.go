@@ -0,0 +1,229 @@
+package checkers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	metricHeapObjects = "/memory/classes/heap/objects:bytes"
+	metricHeapUnused  = "/memory/classes/heap/unused:bytes"
+	metricGCPauses    = "/sched/pauses/total/gc:seconds"
+)
+
+// RuntimeChecker checks the process itself: goroutine count, heap in use
+// versus GOMEMLIMIT, open file descriptors versus RLIMIT_NOFILE, the p99 GC
+// pause since the previous check and, in a cgroup with a memory limit, the
+// memory working set (usage minus inactive file cache) versus that limit. Values beyond a warning threshold make the check
+// DEGRADED and beyond a critical one DOWN, so a liveness probe can restart a
+// leaking process before it runs out of descriptors or gets OOM-killed.
+// A zero threshold is disabled. File descriptors and cgroups are read on
+// Linux only.
+type RuntimeChecker struct {
+	name string
+	must bool
+
+	goroutines [2]float64
+	heap       [2]float64
+	fds        [2]float64
+	gcPause    [2]time.Duration
+	cgroup     [2]float64
+	cgroupDir  string
+
+	mu         sync.Mutex
+	lastPauses []uint64
+}
+
+type RuntimeOption func(*RuntimeChecker)
+
+// WithGoroutineLimits sets thresholds on the number of goroutines.
+// Default: none.
+func WithGoroutineLimits(warning, critical int) RuntimeOption {
+	return func(c *RuntimeChecker) { c.goroutines = [2]float64{float64(warning), float64(critical)} }
+}
+
+// WithHeapLimits sets thresholds on heap in use as a ratio (0-1) of
+// GOMEMLIMIT. Without a memory limit the heap is not judged.
+// Default: 0.9 and 1.
+func WithHeapLimits(warning, critical float64) RuntimeOption {
+	return func(c *RuntimeChecker) { c.heap = [2]float64{warning, critical} }
+}
+
+// WithFDLimits sets thresholds on open file descriptors as a ratio (0-1) of
+// the soft RLIMIT_NOFILE. Default: 0.8 and 0.95.
+func WithFDLimits(warning, critical float64) RuntimeOption {
+	return func(c *RuntimeChecker) { c.fds = [2]float64{warning, critical} }
+}
+
+// WithGCPauseLimits sets thresholds on the p99 stop-the-world GC pause since
+// the previous check. Default: none.
+func WithGCPauseLimits(warning, critical time.Duration) RuntimeOption {
+	return func(c *RuntimeChecker) { c.gcPause = [2]time.Duration{warning, critical} }
+}
+
+// WithCgroupMemoryLimits sets thresholds on the cgroup's memory working set
+// as a ratio (0-1) of its limit. Without a limit the usage is not judged.
+// Default: 0.85 and 0.95.
+func WithCgroupMemoryLimits(warning, critical float64) RuntimeOption {
+	return func(c *RuntimeChecker) { c.cgroup = [2]float64{warning, critical} }
+}
+
+// WithCgroupDir reads memory usage and limit from dir instead of the
+// process's own cgroup under /sys/fs/cgroup. Both cgroup v2 (memory.current,
+// memory.max, memory.stat) and v1 (memory/memory.usage_in_bytes,
+// memory/memory.limit_in_bytes, memory/memory.stat) layouts are understood.
+func WithCgroupDir(dir string) RuntimeOption {
+	return func(c *RuntimeChecker) { c.cgroupDir = dir }
+}
+
+func NewRuntimeChecker(name string, mustOK bool, opts ...RuntimeOption) *RuntimeChecker {
+	c := &RuntimeChecker{
+		name:   name,
+		must:   mustOK,
+		heap:   [2]float64{0.9, 1},
+		fds:    [2]float64{0.8, 0.95},
+		cgroup: [2]float64{0.85, 0.95},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *RuntimeChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+	var v verdict
+
+	goroutines := runtime.NumGoroutine()
+	resp.Details["goroutines"] = strconv.Itoa(goroutines)
+	v.judge("goroutines", float64(goroutines), c.goroutines, strconv.Itoa(goroutines),
+		func(t float64) string { return strconv.Itoa(int(t)) })
+
+	samples := []metrics.Sample{{Name: metricHeapObjects}, {Name: metricHeapUnused}, {Name: metricGCPauses}}
+	metrics.Read(samples)
+
+	heap := samples[0].Value.Uint64() + samples[1].Value.Uint64()
+	resp.Details["heap_inuse"] = strconv.FormatUint(heap, 10)
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		ratio := float64(heap) / float64(limit)
+		resp.Details["gomemlimit"] = strconv.FormatInt(limit, 10)
+		resp.Details["heap_ratio"] = formatRatio(ratio)
+		v.judge("heap/GOMEMLIMIT", ratio, c.heap, formatRatio(ratio), formatRatio)
+	}
+
+	if p99, ok := c.pauseP99(samples[2].Value.Float64Histogram()); ok {
+		resp.Details["gc_pause_p99"] = latency(p99)
+		v.judge("gc pause p99", p99.Seconds(), [2]float64{c.gcPause[0].Seconds(), c.gcPause[1].Seconds()},
+			latency(p99), func(t float64) string { return latency(time.Duration(t * float64(time.Second))) })
+	}
+
+	if open, limit, err := openFDs(); err == nil {
+		ratio := float64(open) / float64(limit)
+		resp.Details["open_fds"] = strconv.Itoa(open)
+		resp.Details["fd_limit"] = strconv.FormatUint(limit, 10)
+		resp.Details["fd_ratio"] = formatRatio(ratio)
+		v.judge("fds/RLIMIT_NOFILE", ratio, c.fds, formatRatio(ratio), formatRatio)
+	}
+
+	if usage, limit, err := cgroupMemory(c.cgroupDir); err == nil {
+		resp.Details["cgroup_memory_usage"] = strconv.FormatUint(usage, 10)
+		if limit > 0 {
+			ratio := float64(usage) / float64(limit)
+			resp.Details["cgroup_memory_limit"] = strconv.FormatUint(limit, 10)
+			resp.Details["cgroup_memory_ratio"] = formatRatio(ratio)
+			v.judge("cgroup memory", ratio, c.cgroup, formatRatio(ratio), formatRatio)
+		}
+	}
+
+	return v.result(resp, "within limits"), nil
+}
+
+// pauseP99 returns the p99 of the GC pauses recorded since the previous call,
+// or since process start on the first call. It reports false when there were
+// none.
+func (c *RuntimeChecker) pauseP99(h *metrics.Float64Histogram) (time.Duration, bool) {
+	c.mu.Lock()
+	prev := c.lastPauses
+	c.lastPauses = append(c.lastPauses[:0:0], h.Counts...)
+	c.mu.Unlock()
+
+	counts := make([]uint64, len(h.Counts))
+	var total uint64
+	for i, n := range h.Counts {
+		if i < len(prev) {
+			n -= prev[i]
+		}
+		counts[i] = n
+		total += n
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	// Bucket i spans h.Buckets[i] to h.Buckets[i+1]; report its upper bound
+	// unless that is +Inf.
+	rank := uint64(math.Ceil(float64(total) * 0.99))
+	var seen uint64
+	for i, n := range counts {
+		if seen += n; seen >= rank {
+			upper := h.Buckets[i+1]
+			if math.IsInf(upper, 1) {
+				upper = h.Buckets[i]
+			}
+			return time.Duration(upper * float64(time.Second)), true
+		}
+	}
+	return 0, false
+}
+
+// verdict collects threshold violations into a DEGRADED or DOWN result.
+type verdict struct {
+	critical, warning []string
+}
+
+// judge compares value against the warning and critical thresholds in limits,
+// skipping zero ones. shown is value as reported; format renders thresholds.
+func (v *verdict) judge(what string, value float64, limits [2]float64, shown string, format func(float64) string) {
+	switch {
+	case limits[1] > 0 && value >= limits[1]:
+		v.critical = append(v.critical, fmt.Sprintf("%s %s reached %s", what, shown, format(limits[1])))
+	case limits[0] > 0 && value >= limits[0]:
+		v.warning = append(v.warning, fmt.Sprintf("%s %s reached %s", what, shown, format(limits[0])))
+	}
+}
+
+func (v *verdict) result(resp *pb.CheckResult, ok string) *pb.CheckResult {
+	switch {
+	case len(v.critical) > 0:
+		return fail(resp, strings.Join(v.critical, ", "), nil)
+	case len(v.warning) > 0:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = strings.Join(v.warning, ", ")
+	default:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = ok
+	}
+	return resp
+}
+
+func formatRatio(r float64) string {
+	return strconv.FormatFloat(r, 'f', 3, 64)
+}
+
+func (c *RuntimeChecker) Name() string {
+	return c.name
+}
+
+func (c *RuntimeChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package checkers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// openFDs counts the entries of /proc/self/fd and returns them with the soft
+// RLIMIT_NOFILE.
+func openFDs() (int, uint64, error) {
+	var rlim syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlim); err != nil {
+		return 0, 0, err
+	}
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, 0, err
+	}
+	// The descriptor ReadDir held open for the listing is among the entries.
+	return len(entries) - 1, rlim.Cur, nil
+}
+
+// cgroupMemory returns the memory working set and limit of the cgroup in dir,
+// or of the process's own cgroup when dir is empty. A zero limit means
+// unlimited.
+func cgroupMemory(dir string) (usage, limit uint64, err error) {
+	if dir != "" {
+		return readCgroupMemory(dir)
+	}
+	if own := ownCgroup("/"); own != "" {
+		if usage, limit, err := readCgroupMemory(own); err == nil {
+			return usage, limit, nil
+		}
+	}
+	return readCgroupMemory(cgroupRoot)
+}
+
+// readCgroupMemory reads the working set as the kubelet computes it: usage
+// minus the inactive file cache the kernel reclaims before it OOM-kills.
+func readCgroupMemory(dir string) (usage, limit uint64, err error) {
+	if usage, err = readCgroupUint(filepath.Join(dir, "memory.current")); err == nil {
+		usage -= min(usage, inactiveFile(filepath.Join(dir, "memory.stat"), "inactive_file"))
+		limit, err = readCgroupUint(filepath.Join(dir, "memory.max"))
+		return usage, limit, err
+	}
+
+	if usage, err = readCgroupUint(filepath.Join(dir, "memory", "memory.usage_in_bytes")); err != nil {
+		return 0, 0, err
+	}
+	usage -= min(usage, inactiveFile(filepath.Join(dir, "memory", "memory.stat"), "total_inactive_file"))
+	limit, err = readCgroupUint(filepath.Join(dir, "memory", "memory.limit_in_bytes"))
+	// cgroup v1 reports "no limit" as a page-aligned value near MaxInt64.
+	if limit >= 1<<62 {
+		limit = 0
+	}
+	return usage, limit, err
+}
+
+// readCgroupUint reads a single-value cgroup file; "max" reads as 0.
+func readCgroupUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	s := string(bytes.TrimSpace(data))
+	if s == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// inactiveFile reads the inactive file cache from memory.stat, or 0 when the
+// file is unavailable.
+func inactiveFile(path, key string) uint64 {
+	stat, err := readFlatKeyed(path)
+	if err != nil {
+		return 0
+	}
+	return stat[key]
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package checkers
+
+import "errors"
+
+var errNotLinux = errors.New("only supported on linux")
+
+func openFDs() (int, uint64, error) {
+	return 0, 0, errNotLinux
+}
+
+func cgroupMemory(string) (uint64, uint64, error) {
+	return 0, 0, errNotLinux
+}
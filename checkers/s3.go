@@ -0,0 +1,313 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+	s3DefaultCanary = ".srvmon-canary"
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4DateFormat = "20060102T150405Z"
+)
+
+// S3Credentials are the static credentials requests are signed with.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Checker checks a bucket on any S3-compatible endpoint with a signed
+// HeadBucket request and, optionally, a put/get/delete round-trip of a
+// canary object. Requests are signed with AWS Signature Version 4; without
+// credentials they are sent anonymously. Each operation reports its latency.
+type S3Checker struct {
+	name     string
+	endpoint *url.URL
+	bucket   string
+	must     bool
+	err      error
+
+	timeout   time.Duration
+	region    string
+	creds     *S3Credentials
+	pathStyle bool
+	canary    string
+	client    *http.Client
+}
+
+type S3Option func(*S3Checker)
+
+// WithS3Timeout sets the deadline for all operations together. Default: 3s.
+func WithS3Timeout(d time.Duration) S3Option {
+	return func(c *S3Checker) { c.timeout = d }
+}
+
+// WithS3Region sets the region used in the signature. Default: us-east-1.
+func WithS3Region(region string) S3Option {
+	return func(c *S3Checker) { c.region = region }
+}
+
+// WithS3Credentials signs requests with creds.
+func WithS3Credentials(creds S3Credentials) S3Option {
+	return func(c *S3Checker) { c.creds = &creds }
+}
+
+// WithS3PathStyle addresses the bucket as <endpoint>/<bucket> instead of
+// <bucket>.<endpoint host>, as MinIO and most self-hosted stores expect.
+func WithS3PathStyle() S3Option {
+	return func(c *S3Checker) { c.pathStyle = true }
+}
+
+// WithS3Canary writes, reads back and deletes an object after HeadBucket.
+// The object key is prefix followed by a random suffix that is new on every
+// check, so replicas probing the same bucket do not race on one object. An
+// empty prefix uses ".srvmon-canary".
+func WithS3Canary(prefix string) S3Option {
+	return func(c *S3Checker) {
+		c.canary = prefix
+		if prefix == "" {
+			c.canary = s3DefaultCanary
+		}
+	}
+}
+
+// WithS3HTTPClient sets the client requests are sent with.
+func WithS3HTTPClient(cli *http.Client) S3Option {
+	return func(c *S3Checker) { c.client = cli }
+}
+
+// NewS3Checker checks bucket at endpoint, e.g. https://s3.eu-west-1.amazonaws.com
+// or http://minio:9000.
+func NewS3Checker(name, endpoint, bucket string, mustOK bool, opts ...S3Option) *S3Checker {
+	c := &S3Checker{
+		name:    name,
+		bucket:  bucket,
+		must:    mustOK,
+		timeout: defaultTimeout,
+		region:  s3DefaultRegion,
+		client:  http.DefaultClient,
+	}
+	c.endpoint, c.err = url.Parse(endpoint)
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *S3Checker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+	if c.err != nil {
+		return fail(resp, "invalid endpoint", c.err), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if _, err := c.do(ctx, resp, "head_bucket", http.MethodHead, "", nil); err != nil {
+		return fail(resp, "head bucket failed", err), nil
+	}
+
+	if c.canary != "" {
+		suffix := make([]byte, 8)
+		_, _ = rand.Read(suffix)
+		key := c.canary + "-" + hex.EncodeToString(suffix)
+		payload := []byte("srvmon canary " + time.Now().UTC().Format(time.RFC3339Nano))
+		if _, err := c.do(ctx, resp, "put", http.MethodPut, key, payload); err != nil {
+			return fail(resp, "put canary failed", err), nil
+		}
+		got, err := c.do(ctx, resp, "get", http.MethodGet, key, nil)
+		if err != nil {
+			return fail(resp, "get canary failed", err), nil
+		}
+		if !bytes.Equal(got, payload) {
+			return fail(resp, "canary content mismatch", nil), nil
+		}
+		if _, err := c.do(ctx, resp, "delete", http.MethodDelete, key, nil); err != nil {
+			return fail(resp, "delete canary failed", err), nil
+		}
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = "bucket " + c.bucket + " reachable"
+	return resp, nil
+}
+
+// do sends one signed request, records its latency under op and returns the
+// response body of a successful request.
+func (c *S3Checker) do(ctx context.Context, resp *pb.CheckResult, op, method, key string, body []byte) ([]byte, error) {
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.objectURL(key), rdr)
+	if err != nil {
+		return nil, err
+	}
+	if c.creds != nil {
+		signV4(req, body, *c.creds, c.region, time.Now())
+	}
+
+	start := time.Now()
+	hr, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = hr.Body.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(hr.Body, defaultMaxBodySize))
+	resp.Details["latency."+op] = latency(time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+
+	if hr.StatusCode < 200 || hr.StatusCode > 299 {
+		var s3err struct {
+			Code    string
+			Message string
+		}
+		if xml.Unmarshal(data, &s3err) == nil && s3err.Code != "" {
+			return nil, fmt.Errorf("%s: %s: %s", hr.Status, s3err.Code, s3err.Message)
+		}
+		return nil, fmt.Errorf("%s", hr.Status)
+	}
+	return data, nil
+}
+
+// objectURL addresses key in the bucket, or the bucket itself for an empty key.
+func (c *S3Checker) objectURL(key string) string {
+	u := *c.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if c.pathStyle {
+		path += "/" + c.bucket
+	} else {
+		u.Host = c.bucket + "." + u.Host
+	}
+	if key != "" || !c.pathStyle {
+		path += "/" + key
+	}
+	u.Path = path
+	u.RawPath = awsEscapePath(path)
+	return u.String()
+}
+
+// signV4 adds AWS Signature Version 4 headers to req for service s3.
+func signV4(req *http.Request, body []byte, creds S3Credentials, region string, now time.Time) {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	date := amzDate[:8]
+
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsEscapePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := sigV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscapePath escapes every path segment as SigV4 requires for S3.
+func awsEscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = awsEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsEscape percent-encodes everything except the RFC 3986 unreserved characters.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if 'A' <= ch && ch <= 'Z' || 'a' <= ch && ch <= 'z' || '0' <= ch && ch <= '9' ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func (c *S3Checker) Name() string {
+	return c.name
+}
+
+func (c *S3Checker) MustOK(_ context.Context) bool {
+	return c.must
+}
@@ -0,0 +1,266 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const maxScriptBuffer = 64 * 1024
+
+type stepKind int
+
+const (
+	stepSend stepKind = iota
+	stepExpect
+	stepStartTLS
+)
+
+// Step is one action of a ScriptChecker script.
+type Step struct {
+	kind    stepKind
+	data    []byte
+	re      *regexp.Regexp
+	tls     *tls.Config
+	timeout time.Duration
+}
+
+// Send writes data to the connection.
+func Send(data string) Step {
+	return Step{kind: stepSend, data: []byte(data)}
+}
+
+// Expect reads until the data received since the previous Expect matches
+// pattern. Named capture groups are copied into the result details. It
+// panics if pattern does not compile.
+func Expect(pattern string) Step {
+	return Step{kind: stepExpect, re: regexp.MustCompile(pattern)}
+}
+
+// StartTLS upgrades the connection to TLS, e.g. after an SMTP STARTTLS
+// command was accepted. A nil cfg uses the system roots, and an empty
+// ServerName defaults to the host of the address.
+func StartTLS(cfg *tls.Config) Step {
+	return Step{kind: stepStartTLS, tls: cfg}
+}
+
+// Within sets the step's own deadline. Steps without one use the script timeout.
+func (s Step) Within(d time.Duration) Step {
+	s.timeout = d
+	return s
+}
+
+func (s Step) String() string {
+	switch s.kind {
+	case stepSend:
+		return "send " + strconv.Quote(string(s.data))
+	case stepExpect:
+		return "expect /" + s.re.String() + "/"
+	default:
+		return "starttls"
+	}
+}
+
+// ScriptChecker connects over TCP and runs a send/expect script, for text
+// protocols that do not warrant a checker of their own. A failing step is
+// reported by number together with the bytes received so far.
+type ScriptChecker struct {
+	name  string
+	addr  string
+	must  bool
+	steps []Step
+
+	timeout time.Duration
+	tls     *tls.Config
+}
+
+type ScriptOption func(*ScriptChecker)
+
+// WithScriptTimeout sets the deadline for connecting and for every step
+// without its own. Default: 3s.
+func WithScriptTimeout(d time.Duration) ScriptOption {
+	return func(c *ScriptChecker) { c.timeout = d }
+}
+
+// WithScriptTLS connects with TLS from the start (implicit TLS).
+func WithScriptTLS(cfg *tls.Config) ScriptOption {
+	return func(c *ScriptChecker) { c.tls = cfg }
+}
+
+func NewScriptChecker(name, addr string, steps []Step, mustOK bool, opts ...ScriptOption) *ScriptChecker {
+	c := &ScriptChecker{name: name, addr: addr, must: mustOK, steps: steps, timeout: defaultTimeout}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// SMTPScript reads the banner, greets with EHLO and quits. With startTLS it
+// also issues STARTTLS, upgrades the connection with cfg as StartTLS does and
+// greets again over TLS.
+func SMTPScript(startTLS bool, cfg *tls.Config) []Step {
+	steps := []Step{
+		Expect(`^220[ -](?P<banner>[^\r\n]*)\r\n`),
+		Send("EHLO srvmon\r\n"),
+		Expect(`(?m)^250 [^\r\n]*\r\n`),
+	}
+	if startTLS {
+		steps = append(steps,
+			Send("STARTTLS\r\n"),
+			Expect(`^220[^\r\n]*\r\n`),
+			StartTLS(cfg),
+			Send("EHLO srvmon\r\n"),
+			Expect(`(?m)^250 [^\r\n]*\r\n`),
+		)
+	}
+	return append(steps, Send("QUIT\r\n"), Expect(`^221`))
+}
+
+// MemcachedScript asks for the server version.
+func MemcachedScript() []Step {
+	return []Step{
+		Send("version\r\n"),
+		Expect(`^VERSION (?P<version>\S+)\r\n`),
+	}
+}
+
+// NATSScript reads the INFO banner, connects and exchanges PING/PONG.
+func NATSScript() []Step {
+	return []Step{
+		Expect(`^INFO [^\r\n]*"version":"(?P<version>[^"]+)"[^\r\n]*\r\n`),
+		Send("CONNECT {\"verbose\":false}\r\nPING\r\n"),
+		Expect(`PONG\r\n`),
+	}
+}
+
+// scriptRun is the state of one script execution.
+type scriptRun struct {
+	conn net.Conn
+	buf  []byte
+}
+
+func (c *ScriptChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	start := time.Now()
+	dialCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.tls != nil {
+		d := tls.Dialer{Config: c.tlsConfig(c.tls)}
+		conn, err = d.DialContext(dialCtx, "tcp", c.addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(dialCtx, "tcp", c.addr)
+	}
+	if err != nil {
+		return fail(resp, "connection failed", err), nil
+	}
+	run := &scriptRun{conn: conn}
+	defer func() {
+		_ = run.conn.Close()
+	}()
+
+	for i, step := range c.steps {
+		timeout := c.timeout
+		if step.timeout > 0 {
+			timeout = step.timeout
+		}
+		deadline := time.Now().Add(timeout)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		_ = run.conn.SetDeadline(deadline)
+
+		if err := c.exec(run, step, resp.Details); err != nil {
+			resp.Details["step"] = strconv.Itoa(i + 1)
+			resp.Details["received"] = quoteTail(run.buf)
+			return fail(resp, fmt.Sprintf("step %d (%s) failed", i+1, step), err), nil
+		}
+	}
+
+	resp.Details["latency"] = latency(time.Since(start))
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = fmt.Sprintf("script completed (%d steps)", len(c.steps))
+	return resp, nil
+}
+
+func (c *ScriptChecker) exec(run *scriptRun, step Step, details map[string]string) error {
+	switch step.kind {
+	case stepSend:
+		_, err := run.conn.Write(step.data)
+		return err
+
+	case stepExpect:
+		chunk := make([]byte, 4096)
+		for {
+			if m := step.re.FindSubmatchIndex(run.buf); m != nil {
+				for i, name := range step.re.SubexpNames() {
+					if name != "" && m[2*i] >= 0 {
+						details[name] = string(run.buf[m[2*i]:m[2*i+1]])
+					}
+				}
+				run.buf = run.buf[m[1]:]
+				return nil
+			}
+			if len(run.buf) >= maxScriptBuffer {
+				return errors.New("no match in the first 64 KiB")
+			}
+			n, err := run.conn.Read(chunk)
+			run.buf = append(run.buf, chunk[:n]...)
+			if err != nil && n == 0 {
+				return err
+			}
+		}
+
+	default:
+		tc := tls.Client(run.conn, c.tlsConfig(step.tls))
+		if err := tc.Handshake(); err != nil {
+			return err
+		}
+		run.conn = tc
+		run.buf = nil
+		return nil
+	}
+}
+
+// tlsConfig fills in the server name from the address. A nil cfg stands for
+// the zero configuration.
+func (c *ScriptChecker) tlsConfig(cfg *tls.Config) *tls.Config {
+	if cfg == nil {
+		cfg = &tls.Config{}
+	} else {
+		cfg = cfg.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName, _, _ = net.SplitHostPort(c.addr)
+	}
+	return cfg
+}
+
+// quoteTail quotes at most the last 512 received bytes.
+func quoteTail(b []byte) string {
+	if len(b) > 512 {
+		b = b[len(b)-512:]
+	}
+	return strconv.Quote(string(b))
+}
+
+func (c *ScriptChecker) Name() string {
+	return c.name
+}
+
+func (c *ScriptChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
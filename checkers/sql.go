@@ -0,0 +1,142 @@
+package checkers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// SQLChecker checks a database/sql pool with PingContext or a validation
+// query and reports the pool statistics. It works with any driver.
+type SQLChecker struct {
+	name string
+	db   *sql.DB
+	must bool
+
+	timeout    time.Duration
+	query      string
+	saturation float64
+	maxWait    time.Duration
+
+	mu   sync.Mutex
+	last sql.DBStats
+}
+
+type SQLOption func(*SQLChecker)
+
+// WithSQLTimeout sets the deadline for the ping or validation query.
+// Default: 3s.
+func WithSQLTimeout(d time.Duration) SQLOption {
+	return func(c *SQLChecker) { c.timeout = d }
+}
+
+// WithValidationQuery runs query instead of PingContext, e.g. "SELECT 1".
+// The rows are read and discarded.
+func WithValidationQuery(query string) SQLOption {
+	return func(c *SQLChecker) { c.query = query }
+}
+
+// WithMaxSaturation turns the check DEGRADED once the share of connections in
+// use reaches ratio (0-1) of the pool's MaxOpenConnections. Pools without a
+// connection limit never saturate.
+func WithMaxSaturation(ratio float64) SQLOption {
+	return func(c *SQLChecker) { c.saturation = ratio }
+}
+
+// WithMaxWait turns the check DEGRADED when callers waited longer than d on
+// average for a connection since the previous check.
+func WithMaxWait(d time.Duration) SQLOption {
+	return func(c *SQLChecker) { c.maxWait = d }
+}
+
+func NewSQLChecker(name string, db *sql.DB, mustOK bool, opts ...SQLOption) *SQLChecker {
+	c := &SQLChecker{name: name, db: db, must: mustOK, timeout: defaultTimeout}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *SQLChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.validate(ctx)
+	elapsed := time.Since(start)
+
+	stats := c.db.Stats()
+	c.mu.Lock()
+	prev := c.last
+	c.last = stats
+	c.mu.Unlock()
+
+	resp.Details["latency"] = latency(elapsed)
+	resp.Details["max_open"] = strconv.Itoa(stats.MaxOpenConnections)
+	resp.Details["open"] = strconv.Itoa(stats.OpenConnections)
+	resp.Details["in_use"] = strconv.Itoa(stats.InUse)
+	resp.Details["idle"] = strconv.Itoa(stats.Idle)
+	resp.Details["wait_count"] = strconv.FormatInt(stats.WaitCount, 10)
+	resp.Details["wait_duration"] = stats.WaitDuration.String()
+
+	if err != nil {
+		if c.query != "" {
+			return fail(resp, "validation query failed", err), nil
+		}
+		return fail(resp, "ping failed", err), nil
+	}
+
+	if c.saturation > 0 && stats.MaxOpenConnections > 0 {
+		used := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		if used >= c.saturation {
+			resp.Status = pb.Status_STATUS_DEGRADED
+			resp.Message = fmt.Sprintf("pool saturated: %d/%d connections in use", stats.InUse, stats.MaxOpenConnections)
+			return resp, nil
+		}
+	}
+
+	if waits := stats.WaitCount - prev.WaitCount; c.maxWait > 0 && waits > 0 {
+		avg := (stats.WaitDuration - prev.WaitDuration) / time.Duration(waits)
+		if avg > c.maxWait {
+			resp.Status = pb.Status_STATUS_DEGRADED
+			resp.Message = fmt.Sprintf("connection wait %s exceeds %s (%d waits)", avg, c.maxWait, waits)
+			return resp, nil
+		}
+	}
+
+	resp.Status = pb.Status_STATUS_UP
+	resp.Message = "database reachable"
+	return resp, nil
+}
+
+func (c *SQLChecker) validate(ctx context.Context) error {
+	if c.query == "" {
+		return c.db.PingContext(ctx)
+	}
+
+	rows, err := c.db.QueryContext(ctx, c.query)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+func (c *SQLChecker) Name() string {
+	return c.name
+}
+
+func (c *SQLChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
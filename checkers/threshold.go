@@ -0,0 +1,228 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// ValueFunc reads the current value of an application gauge or counter.
+type ValueFunc func(ctx context.Context) (float64, error)
+
+type thresholdMode int
+
+const (
+	modeInstant thresholdMode = iota
+	modeAverage
+	modeRate
+)
+
+// ThresholdChecker judges an arbitrary application value, such as a queue
+// backlog, a cache hit rate or a worker pool's saturation, against warning
+// (DEGRADED) and critical (DOWN) bounds. The value can be taken as is,
+// averaged over a rolling window of checks, or turned into a per-second rate
+// of change for counters. The observed value and the bounds are reported in
+// the details.
+type ThresholdChecker struct {
+	name string
+	fn   ValueFunc
+	must bool
+
+	timeout time.Duration
+	unit    string
+	above   *[2]float64
+	below   *[2]float64
+	mode    thresholdMode
+	window  time.Duration
+
+	mu      sync.Mutex
+	samples []thresholdSample
+}
+
+type thresholdSample struct {
+	at    time.Time
+	value float64
+}
+
+type ThresholdOption func(*ThresholdChecker)
+
+// WithThresholdTimeout sets the deadline passed to the value function.
+// Default: 3s.
+func WithThresholdTimeout(d time.Duration) ThresholdOption {
+	return func(c *ThresholdChecker) { c.timeout = d }
+}
+
+// WithAbove turns the check DEGRADED once the value reaches warning and DOWN
+// once it reaches critical, e.g. for backlogs and latencies.
+func WithAbove(warning, critical float64) ThresholdOption {
+	return func(c *ThresholdChecker) { c.above = &[2]float64{warning, critical} }
+}
+
+// WithBelow turns the check DEGRADED once the value drops to warning and
+// DOWN once it drops to critical, e.g. for hit rates and free capacity.
+// It can be combined with WithAbove to keep the value within a band.
+func WithBelow(warning, critical float64) ThresholdOption {
+	return func(c *ThresholdChecker) { c.below = &[2]float64{warning, critical} }
+}
+
+// WithUnit sets the unit the value is displayed with, e.g. "ms", "%" or
+// "items".
+func WithUnit(unit string) ThresholdOption {
+	return func(c *ThresholdChecker) { c.unit = unit }
+}
+
+// WithRollingAverage judges the average of the values read by the checks
+// within window instead of the latest value.
+func WithRollingAverage(window time.Duration) ThresholdOption {
+	return func(c *ThresholdChecker) {
+		c.mode = modeAverage
+		c.window = window
+	}
+}
+
+// WithRate judges the per-second rate of change over window, for counters
+// such as processed or failed items. Until a second value has been read the
+// check is UNKNOWN. With a zero window the rate is taken since the previous
+// check.
+func WithRate(window time.Duration) ThresholdOption {
+	return func(c *ThresholdChecker) {
+		c.mode = modeRate
+		c.window = window
+	}
+}
+
+func NewThresholdChecker(name string, fn ValueFunc, mustOK bool, opts ...ThresholdOption) *ThresholdChecker {
+	c := &ThresholdChecker{name: name, fn: fn, must: mustOK, timeout: defaultTimeout}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *ThresholdChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := newResult(c.name)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	raw, err := c.fn(ctx)
+	if err != nil {
+		return fail(resp, "read value failed", err), nil
+	}
+	if math.IsNaN(raw) {
+		return fail(resp, "read value failed", errors.New("value is NaN")), nil
+	}
+
+	if c.unit != "" {
+		resp.Details["unit"] = c.unit
+	}
+	reportBounds(resp.Details, "above", c.above)
+	reportBounds(resp.Details, "below", c.below)
+
+	value, n, ok := c.observe(time.Now(), raw)
+	if c.mode != modeInstant {
+		resp.Details["raw"] = formatValue(raw)
+		resp.Details["samples"] = strconv.Itoa(n)
+		resp.Details["window"] = c.window.String()
+	}
+	if !ok {
+		resp.Status = pb.Status_STATUS_UNKNOWN
+		resp.Message = "waiting for a second sample"
+		return resp, nil
+	}
+	resp.Details["value"] = formatValue(value)
+
+	shown := c.display(value)
+	switch {
+	case c.above != nil && value >= c.above[1]:
+		return fail(resp, shown+" at or above critical "+c.display(c.above[1]), nil), nil
+	case c.below != nil && value <= c.below[1]:
+		return fail(resp, shown+" at or below critical "+c.display(c.below[1]), nil), nil
+	case c.above != nil && value >= c.above[0]:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = shown + " at or above warning " + c.display(c.above[0])
+	case c.below != nil && value <= c.below[0]:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = shown + " at or below warning " + c.display(c.below[0])
+	default:
+		resp.Status = pb.Status_STATUS_UP
+		resp.Message = shown
+	}
+	return resp, nil
+}
+
+// observe records raw and returns the value to judge, the number of samples
+// it is based on and whether it could be computed.
+func (c *ThresholdChecker) observe(now time.Time, raw float64) (float64, int, bool) {
+	if c.mode == modeInstant {
+		return raw, 1, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.samples = append(c.samples, thresholdSample{at: now, value: raw})
+	// Keep the samples within the window plus, for rates, the newest one
+	// before it as the baseline.
+	keep := 0
+	for keep < len(c.samples)-1 && now.Sub(c.samples[keep].at) > c.window {
+		keep++
+	}
+	if c.mode == modeRate && keep > 0 {
+		keep--
+	}
+	c.samples = c.samples[keep:]
+
+	if c.mode == modeAverage {
+		var sum float64
+		for _, s := range c.samples {
+			sum += s.value
+		}
+		return sum / float64(len(c.samples)), len(c.samples), true
+	}
+
+	first := c.samples[0]
+	elapsed := now.Sub(first.at).Seconds()
+	if len(c.samples) < 2 || elapsed <= 0 {
+		return 0, len(c.samples), false
+	}
+	return (raw - first.value) / elapsed, len(c.samples), true
+}
+
+func reportBounds(details map[string]string, side string, b *[2]float64) {
+	if b == nil {
+		return
+	}
+	details["warning_"+side] = formatValue(b[0])
+	details["critical_"+side] = formatValue(b[1])
+}
+
+// display formats v with the unit, and "/s" for rates.
+func (c *ThresholdChecker) display(v float64) string {
+	s := formatValue(v)
+	if c.unit != "" {
+		s += " " + c.unit
+	}
+	if c.mode == modeRate {
+		s += "/s"
+	}
+	return s
+}
+
+// formatValue rounds v to three decimals and drops trailing zeros.
+func formatValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+func (c *ThresholdChecker) Name() string {
+	return c.name
+}
+
+func (c *ThresholdChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
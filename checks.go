@@ -2,36 +2,56 @@ package srvmon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// evaluation pairs a dependency with the result it produced.
+type evaluation struct {
+	dep    Checker
+	result *pb.CheckResult
+}
+
 func (m *SrvMon) Health(ctx context.Context, _ *pb.HealthRequest) (*pb.HealthResponse, error) {
+	ctx, ok := m.enterTrail(ctx)
+	if !ok {
+		// A federated health request looped back to this instance.
+		return &pb.HealthResponse{
+			Status:    pb.Status_STATUS_UNKNOWN,
+			Version:   m.version,
+			Timestamp: timestamppb.New(time.Now()),
+		}, nil
+	}
+
 	resp := &pb.HealthResponse{
-		Status:  pb.Status_STATUS_UP,
-		Version: m.version,
+		Status:    pb.Status_STATUS_UP,
+		Version:   m.version,
+		Overrides: m.activeOverrides(),
 	}
 
-	var once sync.Once
-	for _, dep := range m.dependencies {
-		check, err := dep.Check(ctx)
-		if err != nil {
-			m.log.Error("dependency check", zap.Error(err))
-			return nil, fmt.Errorf("dependency check: %w", err)
-		}
+	evals, err := m.evaluate(ctx)
+	if err != nil {
+		return nil, err
+	}
 
+	var once sync.Once
+	for _, e := range evals {
+		check := e.result
 		resp.Checks = append(resp.Checks, check)
 
 		if check.Status != pb.Status_STATUS_DOWN {
 			continue
 		}
 
-		if dep.MustOK(ctx) {
+		if e.dep.MustOK(ctx) {
 			once.Do(func() {
 				resp.Status = pb.Status_STATUS_DOWN
 			})
@@ -49,34 +69,43 @@ func (m *SrvMon) Health(ctx context.Context, _ *pb.HealthRequest) (*pb.HealthRes
 
 func (m *SrvMon) Ready(ctx context.Context, _ *pb.ReadinessRequest) (resp *pb.ReadinessResponse, _ error) {
 	resp = &pb.ReadinessResponse{
-		Ready: false,
+		Ready:     false,
+		Overrides: m.activeOverrides(),
 	}
 
 	defer func() {
 		resp.Timestamp = timestamppb.New(time.Now())
 	}()
 
-	if !m.ready.Load() {
+	// A service-level override takes precedence over SetReady and dependencies.
+	forced := m.override("")
+	if forced != nil && forced.Status != pb.Status_STATUS_UP {
+		resp.Reason = "override: " + forced.Reason
+		return resp, nil
+	}
+
+	if forced == nil && !m.ready.Load() {
 		resp.Reason = "service is not ready"
 		return resp, nil
 	}
 
+	ctx, _ = m.enterTrail(ctx)
+	evals, err := m.evaluate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	resp.Ready = true
 
 	var once sync.Once
-	for _, dep := range m.dependencies {
-		check, err := dep.Check(ctx)
-		if err != nil {
-			m.log.Error("dependency check", zap.Error(err))
-			return nil, fmt.Errorf("dependency check: %w", err)
-		}
-
+	for _, e := range evals {
+		check := e.result
 		resp.Checks = append(resp.Checks, check)
 		if check.Status != pb.Status_STATUS_DOWN {
 			continue
 		}
 
-		if dep.MustOK(ctx) {
+		if forced == nil && e.dep.MustOK(ctx) {
 			once.Do(func() {
 				resp.Ready = false
 				resp.Reason = check.Message
@@ -86,3 +115,141 @@ func (m *SrvMon) Ready(ctx context.Context, _ *pb.ReadinessRequest) (resp *pb.Re
 
 	return resp, nil
 }
+
+// evaluate runs every dependency and returns the results in registration order.
+// Parents declared with DependsOn are checked before their children; a child
+// whose parent is DOWN (or was itself skipped) is not executed. Overridden
+// checks report the override instead of running. Concurrent evaluations share
+// in-flight executions, and checkers with a result TTL may be served from cache.
+func (m *SrvMon) evaluate(ctx context.Context) ([]evaluation, error) {
+	byName := m.indexByName()
+
+	evals := make([]evaluation, len(m.dependencies))
+	// skippedBy holds the name of the DOWN ancestor that caused a skip.
+	skippedBy := make([]string, len(m.dependencies))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		if evals[i].result != nil {
+			return nil
+		}
+
+		dep := m.dependencies[i]
+		evals[i].dep = dep
+
+		name := nameOf(dep)
+		if name != "" {
+			if o := m.override(name); o != nil {
+				evals[i].result = overrideResult(name, o)
+				describe(dep, evals[i].result)
+				return nil
+			}
+		}
+
+		for _, parent := range m.parents[name] {
+			j, ok := byName[parent]
+			if !ok {
+				continue
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+
+			cause := skippedBy[j]
+			if cause == "" && evals[j].result.Status == pb.Status_STATUS_DOWN {
+				cause = parent
+			}
+			if cause != "" {
+				skippedBy[i] = cause
+				evals[i].result = &pb.CheckResult{
+					Name:      name,
+					Status:    pb.Status_STATUS_UNKNOWN,
+					Message:   "skipped: parent " + cause + " down",
+					Timestamp: timestamppb.New(time.Now()),
+				}
+				describe(dep, evals[i].result)
+				return nil
+			}
+		}
+
+		check, err := m.probes[i].run(ctx, dep, m.resultTTL(name, dep))
+		if err != nil {
+			m.log.Error("dependency check", zap.Error(err))
+			return fmt.Errorf("dependency check: %w", err)
+		}
+		evals[i].result = check
+
+		return nil
+	}
+
+	for i := range m.dependencies {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return evals, nil
+}
+
+// RunChecks executes the named checks, or all of them when no names are given,
+// right away. Overrides and the dependency graph are bypassed so operators see
+// the real state; concurrent runs of the same check share one execution.
+func (m *SrvMon) RunChecks(ctx context.Context, req *pb.RunChecksRequest) (*pb.RunChecksResponse, error) {
+	ctx, _ = m.enterTrail(ctx)
+
+	var indexes []int
+	if len(req.GetNames()) == 0 {
+		for i := range m.dependencies {
+			indexes = append(indexes, i)
+		}
+	} else {
+		byName := m.indexByName()
+		for _, name := range req.GetNames() {
+			i, ok := byName[name]
+			if !ok {
+				return nil, status.Errorf(codes.NotFound, "check %q not found", name)
+			}
+			indexes = append(indexes, i)
+		}
+	}
+
+	resp := &pb.RunChecksResponse{
+		Checks: make([]*pb.CheckResult, len(indexes)),
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(indexes))
+	)
+	for n, i := range indexes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp.Checks[n], errs[n] = m.probes[i].run(ctx, m.dependencies[i], 0)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		m.log.Error("dependency check", zap.Error(err))
+		return nil, fmt.Errorf("dependency check: %w", err)
+	}
+
+	resp.Timestamp = timestamppb.New(time.Now())
+
+	return resp, nil
+}
+
+// indexByName maps the names of Named dependencies to their position.
+// When names collide, the first registered dependency wins.
+func (m *SrvMon) indexByName() map[string]int {
+	byName := make(map[string]int, len(m.dependencies))
+	for i, dep := range m.dependencies {
+		if name := nameOf(dep); name != "" {
+			if _, ok := byName[name]; !ok {
+				byName[name] = i
+			}
+		}
+	}
+	return byName
+}
@@ -32,11 +32,22 @@ const (
 )
 
 type checkResult struct {
-	Name      string `json:"name"`
-	Status    string `json:"status"`
-	Message   string `json:"message"`
-	Error     string `json:"error"`
-	Timestamp string `json:"timestamp"`
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	Message   string        `json:"message"`
+	Error     string        `json:"error"`
+	Timestamp string        `json:"timestamp"`
+	Children  []checkResult `json:"children"`
+	Cached    bool          `json:"cached"`
+	Age       string        `json:"age"`
+}
+
+type override struct {
+	Check       string `json:"check"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason"`
+	Maintenance bool   `json:"maintenance"`
+	ExpiresAt   string `json:"expiresAt"`
 }
 
 type healthResponse struct {
@@ -44,6 +55,7 @@ type healthResponse struct {
 	Version   string        `json:"version"`
 	Checks    []checkResult `json:"checks"`
 	Timestamp string        `json:"timestamp"`
+	Overrides []override    `json:"overrides"`
 }
 
 type readinessResponse struct {
@@ -51,6 +63,7 @@ type readinessResponse struct {
 	Reason    string        `json:"reason"`
 	Checks    []checkResult `json:"checks"`
 	Timestamp string        `json:"timestamp"`
+	Overrides []override    `json:"overrides"`
 }
 
 func statusIcon(status string) (string, string) {
@@ -99,6 +112,12 @@ func fetch(url string, timeout time.Duration) ([]byte, error) {
 }
 
 func renderChecks(b *strings.Builder, checks []checkResult) {
+	renderTree(b, checks, "")
+}
+
+// renderTree draws one level of the check tree; indent carries the
+// guide lines of the enclosing levels.
+func renderTree(b *strings.Builder, checks []checkResult, indent string) {
 	if len(checks) == 0 {
 		return
 	}
@@ -113,21 +132,46 @@ func renderChecks(b *strings.Builder, checks []checkResult) {
 	for i, c := range checks {
 		icon, label := statusIcon(c.Status)
 		connector := "├"
+		padding := "│"
 		if i == len(checks)-1 {
 			connector = "└"
+			padding = " "
 		}
-		_, _ = fmt.Fprintf(b, "  %s%s──%s %s%-*s%s  %s %s", dim, connector, reset, bold, nameW, c.Name, reset, icon, label)
+		_, _ = fmt.Fprintf(b, "  %s%s%s──%s %s%-*s%s  %s %s", dim, indent, connector, reset, bold, nameW, c.Name, reset, icon, label)
 		if c.Message != "" {
 			_, _ = fmt.Fprintf(b, "  %s%s%s", dim, c.Message, reset)
 		}
+		if c.Cached {
+			_, _ = fmt.Fprintf(b, "  %s(cached %s ago)%s", dim, c.Age, reset)
+		}
 		b.WriteString("\n")
 		if c.Error != "" {
-			padding := "│"
-			if i == len(checks)-1 {
-				padding = " "
-			}
-			_, _ = fmt.Fprintf(b, "  %s%s%s     %s%s%s\n", dim, padding, reset, red, c.Error, reset)
+			_, _ = fmt.Fprintf(b, "  %s%s%s%s     %s%s%s\n", dim, indent, padding, reset, red, c.Error, reset)
 		}
+		renderTree(b, c.Children, indent+padding+"   ")
+	}
+}
+
+// renderOverrides lists active manual overrides so they are hard to forget.
+func renderOverrides(b *strings.Builder, overrides []override) {
+	for _, o := range overrides {
+		target := o.Check
+		if target == "" {
+			target = "readiness"
+		}
+		kind := "override"
+		if o.Maintenance {
+			kind = "maintenance"
+		}
+		_, label := statusIcon(o.Status)
+		_, _ = fmt.Fprintf(b, "  %s▲ %s%s %s%s%s %s", yellow, kind, reset, bold, target, reset, label)
+		if o.Reason != "" {
+			_, _ = fmt.Fprintf(b, "  %s%s%s", dim, o.Reason, reset)
+		}
+		if t, err := time.Parse(time.RFC3339Nano, o.ExpiresAt); err == nil {
+			_, _ = fmt.Fprintf(b, "  %suntil %s%s", dim, t.Local().Format("15:04:05"), reset)
+		}
+		b.WriteString("\n")
 	}
 }
 
@@ -160,6 +204,7 @@ func render(addr string, timeout time.Duration) string {
 		b.WriteString(fmt.Sprintf("  %s%s%s", dim, r.Reason, reset))
 	}
 	b.WriteString("\n")
+	renderOverrides(&b, r.Overrides)
 
 	// 2. If not ready — show readiness checks (if any) and stop
 	if !r.Ready {
@@ -293,7 +338,9 @@ func renderHealth(h healthResponse) string {
 	if h.Version != "" {
 		b.WriteString(fmt.Sprintf("  %s%s%s", dim, h.Version, reset))
 	}
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	renderOverrides(&b, h.Overrides)
+	b.WriteString("\n")
 	renderChecks(&b, h.Checks)
 	b.WriteString("\n")
 	return b.String()
@@ -306,6 +353,7 @@ func renderReady(r readinessResponse) string {
 		b.WriteString(fmt.Sprintf("  %s%s%s", dim, r.Reason, reset))
 	}
 	b.WriteString("\n")
+	renderOverrides(&b, r.Overrides)
 	if len(r.Checks) > 0 {
 		b.WriteString("\n")
 		renderChecks(&b, r.Checks)
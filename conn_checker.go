@@ -2,6 +2,8 @@ package srvmon
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
@@ -14,11 +16,20 @@ import (
 )
 
 type ConnChecker struct {
-	conn    *grpc.ClientConn
-	name    string
-	must    bool
-	timeout time.Duration
-	service string
+	conn     *grpc.ClientConn
+	name     string
+	must     bool
+	timeout  time.Duration
+	service  string
+	services []string
+
+	watch      bool
+	backoffMin time.Duration
+	backoffMax time.Duration
+	grace      time.Duration
+	watchers   []*watcher
+	stop       context.CancelFunc
+	wg         sync.WaitGroup
 }
 
 type ConnCheckerOption func(*ConnChecker)
@@ -35,42 +46,117 @@ func WithService(name string) ConnCheckerOption {
 	return func(c *ConnChecker) { c.service = name }
 }
 
+// WithServices checks several service names over the same connection and
+// reports each of them as a nested result. The check takes the worst status
+// of its services. Overrides WithService.
+func WithServices(names ...string) ConnCheckerOption {
+	return func(c *ConnChecker) { c.services = names }
+}
+
+// WithWatch keeps a grpc.health.v1 Watch stream open per service instead of
+// calling Check on every probe, so Check answers from the latest status
+// received. Call Close to stop the streams.
+func WithWatch() ConnCheckerOption {
+	return func(c *ConnChecker) { c.watch = true }
+}
+
+// WithWatchBackoff sets the delay bounds between attempts to reopen a broken
+// Watch stream. The delay doubles after each failed attempt. It panics unless
+// 0 < base <= maxDelay.
+// Default: 1s to 30s.
+func WithWatchBackoff(base, maxDelay time.Duration) ConnCheckerOption {
+	if base <= 0 || maxDelay < base {
+		panic(fmt.Sprintf("srvmon: invalid watch backoff %s to %s", base, maxDelay))
+	}
+	return func(c *ConnChecker) {
+		c.backoffMin = base
+		c.backoffMax = maxDelay
+	}
+}
+
+// WithWatchGrace sets how long a broken Watch stream reports DEGRADED with
+// the last status received before the check turns DOWN.
+// Default: 30s.
+func WithWatchGrace(d time.Duration) ConnCheckerOption {
+	return func(c *ConnChecker) { c.grace = d }
+}
+
 func NewConnChecker(conn *grpc.ClientConn, name string, mustOK bool, opts ...ConnCheckerOption) *ConnChecker {
-	c := &ConnChecker{conn: conn, name: name, must: mustOK, timeout: 3 * time.Second}
+	c := &ConnChecker{
+		conn:       conn,
+		name:       name,
+		must:       mustOK,
+		timeout:    3 * time.Second,
+		backoffMin: time.Second,
+		backoffMax: 30 * time.Second,
+		grace:      30 * time.Second,
+	}
 	for _, o := range opts {
 		o(c)
 	}
+	if c.watch {
+		c.startWatch()
+	}
 	return c
 }
 
 func (c *ConnChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
-	resp := &pb.CheckResult{
-		Name:      c.name,
-		Timestamp: timestamppb.New(time.Now()),
-	}
-
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
+	if c.watch {
+		return c.checkWatch(ctx), nil
+	}
+
 	c.conn.Connect()
 
+	if len(c.services) == 0 {
+		return c.checkService(ctx, c.name, c.service), nil
+	}
+
+	children := make([]*pb.CheckResult, len(c.services))
+	var wg sync.WaitGroup
+	for i, service := range c.services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			children[i] = c.checkService(ctx, service, service)
+		}()
+	}
+	wg.Wait()
+
+	return c.nest(children), nil
+}
+
+// checkService calls grpc.health.v1 Check for one service.
+func (c *ConnChecker) checkService(ctx context.Context, name, service string) *pb.CheckResult {
+	resp := &pb.CheckResult{
+		Name:      name,
+		Timestamp: timestamppb.New(time.Now()),
+	}
+
 	client := grpc_health_v1.NewHealthClient(c.conn)
 	hr, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{
-		Service: c.service,
+		Service: service,
 	})
 
 	if err != nil {
 		// If the server doesn't implement grpc.health.v1, fall back to connection state check.
 		if s, ok := status.FromError(err); ok && s.Code() == codes.Unimplemented {
-			return c.checkConnState(resp), nil
+			return c.checkConnState(resp)
 		}
 		resp.Status = pb.Status_STATUS_DOWN
 		resp.Message = "health check failed"
 		resp.Error = err.Error()
-		return resp, nil
+		return resp
 	}
 
-	switch hr.GetStatus() {
+	return servingResult(resp, hr.GetStatus())
+}
+
+// servingResult maps a grpc.health.v1 serving status onto resp.
+func servingResult(resp *pb.CheckResult, st grpc_health_v1.HealthCheckResponse_ServingStatus) *pb.CheckResult {
+	switch st {
 	case grpc_health_v1.HealthCheckResponse_SERVING:
 		resp.Status = pb.Status_STATUS_UP
 		resp.Message = "SERVING"
@@ -82,10 +168,17 @@ func (c *ConnChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
 		resp.Message = "SERVICE_UNKNOWN"
 	default:
 		resp.Status = pb.Status_STATUS_UNKNOWN
-		resp.Message = hr.GetStatus().String()
+		resp.Message = st.String()
 	}
 
-	return resp, nil
+	return resp
+}
+
+// nest wraps per-service results into one result carrying the worst status.
+func (c *ConnChecker) nest(children []*pb.CheckResult) *pb.CheckResult {
+	resp, serving := WorstOf(c.name, children)
+	resp.Message = fmt.Sprintf("%d/%d services serving", serving, len(children))
+	return resp
 }
 
 // checkConnState falls back to checking the raw gRPC connection state
@@ -106,6 +199,10 @@ func (c *ConnChecker) checkConnState(resp *pb.CheckResult) *pb.CheckResult {
 	return resp
 }
 
+func (c *ConnChecker) Name() string {
+	return c.name
+}
+
 func (c *ConnChecker) MustOK(_ context.Context) bool {
 	return c.must
 }
@@ -0,0 +1,182 @@
+package srvmon
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watcher follows the serving status of one service over a Watch stream.
+type watcher struct {
+	service string
+
+	// first is closed once the stream delivered a status or failed.
+	first     chan struct{}
+	firstOnce sync.Once
+
+	mu            sync.Mutex
+	status        grpc_health_v1.HealthCheckResponse_ServingStatus
+	seen          bool
+	err           error
+	unimplemented bool
+	changed       time.Time
+	reconnects    int
+}
+
+// startWatch opens one Watch stream per service. The streams live until Close.
+func (c *ConnChecker) startWatch() {
+	services := c.services
+	if len(services) == 0 {
+		services = []string{c.service}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stop = cancel
+
+	client := grpc_health_v1.NewHealthClient(c.conn)
+	for _, service := range services {
+		w := &watcher{service: service, first: make(chan struct{})}
+		c.watchers = append(c.watchers, w)
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.follow(ctx, client, w)
+		}()
+	}
+}
+
+// Close stops the Watch streams started by WithWatch. It does not close the
+// underlying connection.
+func (c *ConnChecker) Close() error {
+	if c.stop != nil {
+		c.stop()
+		c.wg.Wait()
+	}
+	return nil
+}
+
+// follow keeps a Watch stream open for w, reopening it with jittered
+// exponential backoff whenever it breaks.
+func (c *ConnChecker) follow(ctx context.Context, client grpc_health_v1.HealthClient, w *watcher) {
+	delay := c.backoffMin
+	for {
+		stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: w.service})
+		for err == nil {
+			var hr *grpc_health_v1.HealthCheckResponse
+			if hr, err = stream.Recv(); err == nil {
+				w.update(hr.GetStatus())
+				delay = c.backoffMin
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		w.broken(err)
+
+		jitter := time.Duration(rand.Int64N(int64(delay)/2 + 1))
+		select {
+		case <-time.After(delay/2 + jitter):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, c.backoffMax)
+	}
+}
+
+func (w *watcher) update(st grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	w.mu.Lock()
+	if !w.seen || w.status != st || w.err != nil {
+		w.changed = time.Now()
+	}
+	w.status = st
+	w.seen = true
+	w.err = nil
+	w.unimplemented = false
+	w.mu.Unlock()
+
+	w.firstOnce.Do(func() { close(w.first) })
+}
+
+func (w *watcher) broken(err error) {
+	w.mu.Lock()
+	if w.err == nil {
+		w.changed = time.Now()
+	}
+	if w.seen {
+		w.reconnects++
+	}
+	w.err = err
+	w.unimplemented = status.Code(err) == codes.Unimplemented
+	w.mu.Unlock()
+
+	w.firstOnce.Do(func() { close(w.first) })
+}
+
+// checkWatch reports the latest status of every watched service. Until a
+// stream has produced its first answer, Check waits for it within the timeout.
+func (c *ConnChecker) checkWatch(ctx context.Context) *pb.CheckResult {
+	if len(c.services) == 0 {
+		return c.watchResult(ctx, c.name, c.watchers[0])
+	}
+
+	children := make([]*pb.CheckResult, len(c.watchers))
+	for i, w := range c.watchers {
+		children[i] = c.watchResult(ctx, w.service, w)
+	}
+	return c.nest(children)
+}
+
+func (c *ConnChecker) watchResult(ctx context.Context, name string, w *watcher) *pb.CheckResult {
+	resp := &pb.CheckResult{
+		Name:      name,
+		Timestamp: timestamppb.New(time.Now()),
+	}
+
+	select {
+	case <-w.first:
+	case <-ctx.Done():
+		resp.Status = pb.Status_STATUS_UNKNOWN
+		resp.Message = "waiting for first status"
+		return resp
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	resp.Details = map[string]string{
+		"mode":       "watch",
+		"since":      w.changed.UTC().Format(time.RFC3339),
+		"reconnects": strconv.Itoa(w.reconnects),
+	}
+
+	switch {
+	case w.unimplemented:
+		// The server does not implement grpc.health.v1.
+		return c.checkConnState(resp)
+	case w.err != nil && !w.seen:
+		resp.Status = pb.Status_STATUS_DOWN
+		resp.Message = "watch failed"
+		resp.Error = w.err.Error()
+	case w.err != nil:
+		resp.Status = pb.Status_STATUS_DEGRADED
+		resp.Message = "reconnecting"
+		if broken := time.Since(w.changed); broken >= c.grace {
+			resp.Status = pb.Status_STATUS_DOWN
+			resp.Message = "watch broken for " + broken.Round(time.Millisecond).String()
+		}
+		resp.Error = w.err.Error()
+		resp.Details["last_status"] = w.status.String()
+	default:
+		servingResult(resp, w.status)
+	}
+	return resp
+}
@@ -2,51 +2,18 @@ package main
 
 import (
 	"context"
-	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/s4bb4t/srvmon"
-	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"github.com/s4bb4t/srvmon/checkers"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// PingChecker checks TCP connectivity to a host:port.
-type PingChecker struct {
-	name     string
-	addr     string
-	timeout  time.Duration
-	critical bool
-}
-
-func NewPingChecker(name, addr string, timeout time.Duration, critical bool) *PingChecker {
-	return &PingChecker{name: name, addr: addr, timeout: timeout, critical: critical}
-}
-
-func (c *PingChecker) MustOK(_ context.Context) bool { return c.critical }
-
-func (c *PingChecker) Check(_ context.Context) (*pb.CheckResult, error) {
-	result := &pb.CheckResult{Name: c.name, Timestamp: timestamppb.Now()}
-
-	conn, err := net.DialTimeout("tcp", c.addr, c.timeout)
-	if err != nil {
-		result.Status = pb.Status_STATUS_DOWN
-		result.Message = "connection failed"
-		result.Error = err.Error()
-		return result, nil
-	}
-	_ = conn.Close()
-
-	result.Status = pb.Status_STATUS_UP
-	result.Message = "connection successful"
-	return result, nil
-}
-
 func main() {
 	logger, err := zap.NewProduction()
 	if err != nil {
@@ -73,15 +40,22 @@ func main() {
 	monitor := srvmon.New(cfg, logger)
 	monitor.AddDependencies(
 		// Critical: TCP check for Redis
-		NewPingChecker("redis", "localhost:6379", 5*time.Second, true),
+		checkers.NewTCPChecker("redis", "localhost:6379", true, checkers.WithDialTimeout(5*time.Second)),
 		// Critical: gRPC health check for another microservice
 		srvmon.NewConnChecker(otherSvcConn, "other-service", true,
 			srvmon.WithTimeout(2*time.Second),
 		),
 		// Non-critical: external API
-		NewPingChecker("external-api", "api.example.com:443", 10*time.Second, false),
+		checkers.NewTCPChecker("external-api", "api.example.com:443", false, checkers.WithDialTimeout(10*time.Second)),
+		// Non-critical: DNS resolver the external API depends on
+		checkers.NewTCPChecker("dns", "1.1.1.1:53", false, checkers.WithDialTimeout(time.Second)),
 	)
 
+	// Skip the external API check while DNS is down.
+	if err := monitor.DependsOn("external-api", "dns"); err != nil {
+		logger.Fatal("dependency graph", zap.Error(err))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -0,0 +1,156 @@
+package srvmon
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Checkers may implement the optional interfaces below, next to Named, to
+// declare metadata without changing Checker itself. srvmon discovers them on
+// every evaluation and copies the metadata into the results.
+type (
+	// Describer is implemented by checkers that explain what they check.
+	// The description is reported in each result.
+	Describer interface {
+		Description() string
+	}
+
+	// Tagged is implemented by checkers that carry labels such as "database"
+	// or "critical-path". The tags are reported in each result.
+	Tagged interface {
+		Tags() []string
+	}
+
+	// Intervaled is implemented by checkers that only need to run every
+	// Interval. Health and Ready reuse their result for that long unless
+	// SetResultTTL was called for the checker's name.
+	Intervaled interface {
+		Interval() time.Duration
+	}
+)
+
+// CheckerFunc checks a dependency and reports only its status and a message.
+// A non-nil error with an UNSPECIFIED or UP status is reported as DOWN, and an
+// UNSPECIFIED status without an error as UP.
+type CheckerFunc func(ctx context.Context) (pb.Status, string, error)
+
+// FuncChecker adapts a CheckerFunc to Checker, filling in the name,
+// timestamp and duration of each result. Create it with NewCheck.
+type FuncChecker struct {
+	name     string
+	fn       CheckerFunc
+	must     bool
+	desc     string
+	tags     []string
+	interval time.Duration
+}
+
+type CheckOption func(*FuncChecker)
+
+// WithMustOK sets whether the service is not ready while the check fails.
+// Default: true.
+func WithMustOK(mustOK bool) CheckOption {
+	return func(c *FuncChecker) { c.must = mustOK }
+}
+
+// WithDescription sets the description reported with each result.
+func WithDescription(desc string) CheckOption {
+	return func(c *FuncChecker) { c.desc = desc }
+}
+
+// WithTags adds tags reported with each result.
+func WithTags(tags ...string) CheckOption {
+	return func(c *FuncChecker) { c.tags = append(c.tags, tags...) }
+}
+
+// WithInterval lets Health and Ready reuse the result for d. Default: 0, the
+// check runs on every probe.
+func WithInterval(d time.Duration) CheckOption {
+	return func(c *FuncChecker) { c.interval = d }
+}
+
+// NewCheck returns a Checker named name that calls fn.
+func NewCheck(name string, fn CheckerFunc, opts ...CheckOption) *FuncChecker {
+	c := &FuncChecker{name: name, fn: fn, must: true}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *FuncChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	start := time.Now()
+	st, msg, err := c.fn(ctx)
+
+	resp := &pb.CheckResult{
+		Name:      c.name,
+		Status:    st,
+		Message:   msg,
+		Timestamp: timestamppb.New(start),
+		Duration:  durationpb.New(time.Since(start)),
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		if st == pb.Status_STATUS_UNSPECIFIED || st == pb.Status_STATUS_UP {
+			resp.Status = pb.Status_STATUS_DOWN
+		}
+		if resp.Message == "" {
+			resp.Message = "check failed"
+		}
+	} else if st == pb.Status_STATUS_UNSPECIFIED {
+		resp.Status = pb.Status_STATUS_UP
+	}
+
+	return resp, nil
+}
+
+func (c *FuncChecker) Name() string {
+	return c.name
+}
+
+func (c *FuncChecker) Description() string {
+	return c.desc
+}
+
+func (c *FuncChecker) Tags() []string {
+	return slices.Clone(c.tags)
+}
+
+func (c *FuncChecker) Interval() time.Duration {
+	return c.interval
+}
+
+func (c *FuncChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
+
+// describe copies the metadata dep declares through the optional interfaces
+// into res, keeping whatever the checker already set itself.
+func describe(dep Checker, res *pb.CheckResult) {
+	if res.Name == "" {
+		res.Name = nameOf(dep)
+	}
+	if d, ok := dep.(Describer); ok && res.Description == "" {
+		res.Description = d.Description()
+	}
+	if t, ok := dep.(Tagged); ok && len(res.Tags) == 0 {
+		res.Tags = t.Tags()
+	}
+}
+
+// resultTTL returns how long the result of dep may be reused: the TTL set
+// with SetResultTTL, or else the interval dep declares.
+func (m *SrvMon) resultTTL(name string, dep Checker) time.Duration {
+	if ttl, ok := m.ttls[name]; ok {
+		return ttl
+	}
+	if iv, ok := dep.(Intervaled); ok {
+		return iv.Interval()
+	}
+	return 0
+}
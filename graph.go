@@ -0,0 +1,124 @@
+package srvmon
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrDependencyCycle is returned by DependsOn when the new edges would make
+// a checker transitively depend on itself.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// Named is implemented by checkers that know their name before a check runs.
+// Only named checkers can take part in the dependency graph.
+type Named interface {
+	Name() string
+}
+
+// nameOf returns the declared name of c, or an empty string if c is not Named.
+func nameOf(c Checker) string {
+	if n, ok := c.(Named); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// DependsOn declares that the checker named child depends on the checkers
+// named parents. When a parent reports DOWN, the child is not executed and is
+// reported as UNKNOWN instead. Names may refer to checkers that are added later.
+func (m *SrvMon) DependsOn(child string, parents ...string) error {
+	for _, p := range parents {
+		if path := m.pathTo(p, child); path != nil {
+			return fmt.Errorf("%w: %s -> %s", ErrDependencyCycle, child, strings.Join(path, " -> "))
+		}
+	}
+
+	if m.parents == nil {
+		m.parents = make(map[string][]string)
+	}
+
+	for _, p := range parents {
+		if !slices.Contains(m.parents[child], p) {
+			m.parents[child] = append(m.parents[child], p)
+		}
+	}
+
+	return nil
+}
+
+// pathTo returns the chain of parent edges leading from 'from' to 'to',
+// or nil if 'to' is not reachable.
+func (m *SrvMon) pathTo(from, to string) []string {
+	if from == to {
+		return []string{from}
+	}
+	for _, p := range m.parents[from] {
+		if path := m.pathTo(p, to); path != nil {
+			return append([]string{from}, path...)
+		}
+	}
+	return nil
+}
+
+// graphNodes returns named checkers in registration order followed by
+// parents that were declared but never registered.
+func (m *SrvMon) graphNodes() []string {
+	var nodes []string
+	for _, dep := range m.dependencies {
+		if name := nameOf(dep); name != "" && !slices.Contains(nodes, name) {
+			nodes = append(nodes, name)
+		}
+	}
+	for i := 0; i < len(nodes); i++ {
+		for _, p := range m.parents[nodes[i]] {
+			if !slices.Contains(nodes, p) {
+				nodes = append(nodes, p)
+			}
+		}
+	}
+	return nodes
+}
+
+// DOT renders the dependency graph in Graphviz DOT format.
+// Edges point from a parent to the checkers that depend on it.
+func (m *SrvMon) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph srvmon {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	nodes := m.graphNodes()
+	for _, n := range nodes {
+		_, _ = fmt.Fprintf(&b, "  %q;\n", n)
+	}
+	for _, child := range nodes {
+		for _, p := range m.parents[child] {
+			_, _ = fmt.Fprintf(&b, "  %q -> %q;\n", p, child)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the dependency graph as a Mermaid flowchart.
+// Edges point from a parent to the checkers that depend on it.
+func (m *SrvMon) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	nodes := m.graphNodes()
+	ids := make(map[string]string, len(nodes))
+	for i, n := range nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+		_, _ = fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n], strings.ReplaceAll(n, `"`, "#quot;"))
+	}
+	for _, child := range nodes {
+		for _, p := range m.parents[child] {
+			_, _ = fmt.Fprintf(&b, "  %s --> %s\n", ids[p], ids[child])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package srvmon
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GroupPolicy decides whether a group is healthy given how many of its
+// members are healthy. A member is healthy when it reports UP or DEGRADED.
+type GroupPolicy struct {
+	desc string
+	ok   func(healthy, total int) bool
+}
+
+// AllOf requires every member to be healthy.
+func AllOf() GroupPolicy {
+	return GroupPolicy{desc: "all", ok: func(healthy, total int) bool { return healthy == total }}
+}
+
+// AnyOf requires at least one healthy member.
+func AnyOf() GroupPolicy {
+	return GroupPolicy{desc: "any", ok: func(healthy, _ int) bool { return healthy > 0 }}
+}
+
+// AtLeast requires at least n healthy members.
+func AtLeast(n int) GroupPolicy {
+	return GroupPolicy{desc: fmt.Sprintf("at least %d", n), ok: func(healthy, _ int) bool { return healthy >= n }}
+}
+
+// PercentHealthy requires at least pct percent (0-100) of members to be healthy.
+func PercentHealthy(pct float64) GroupPolicy {
+	return GroupPolicy{desc: fmt.Sprintf("%g%%", pct), ok: func(healthy, total int) bool {
+		return total > 0 && float64(healthy)*100 >= pct*float64(total)
+	}}
+}
+
+// GroupChecker checks several replicas of one dependency and reports them as
+// nested results. The group is UP when all members are UP, DEGRADED when the
+// policy still holds despite failing or degraded members, and DOWN otherwise.
+type GroupChecker struct {
+	name    string
+	must    bool
+	policy  GroupPolicy
+	members []Checker
+}
+
+func NewGroupChecker(name string, mustOK bool, policy GroupPolicy, members ...Checker) *GroupChecker {
+	return &GroupChecker{name: name, must: mustOK, policy: policy, members: members}
+}
+
+func (g *GroupChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := &pb.CheckResult{
+		Name:      g.name,
+		Timestamp: timestamppb.New(time.Now()),
+		Children:  make([]*pb.CheckResult, len(g.members)),
+	}
+
+	var wg sync.WaitGroup
+	for i, member := range g.members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child, err := member.Check(ctx)
+			if err != nil {
+				name := nameOf(member)
+				if name == "" {
+					name = fmt.Sprintf("%s[%d]", g.name, i)
+				}
+				child = &pb.CheckResult{
+					Name:      name,
+					Status:    pb.Status_STATUS_DOWN,
+					Message:   "check failed",
+					Error:     err.Error(),
+					Timestamp: timestamppb.New(time.Now()),
+				}
+			}
+			describe(member, child)
+			resp.Children[i] = child
+		}()
+	}
+	wg.Wait()
+
+	healthy, up := 0, 0
+	for _, child := range resp.Children {
+		switch child.Status {
+		case pb.Status_STATUS_UP:
+			healthy++
+			up++
+		case pb.Status_STATUS_DEGRADED:
+			healthy++
+		}
+	}
+
+	total := len(resp.Children)
+	switch {
+	case !g.policy.ok(healthy, total):
+		resp.Status = pb.Status_STATUS_DOWN
+	case up < total:
+		resp.Status = pb.Status_STATUS_DEGRADED
+	default:
+		resp.Status = pb.Status_STATUS_UP
+	}
+	resp.Message = fmt.Sprintf("%d/%d healthy, requires %s", healthy, total, g.policy.desc)
+
+	return resp, nil
+}
+
+// WorstOf wraps children into one result named name that carries their
+// worst status: DOWN if any child is DOWN, otherwise DEGRADED if any child is
+// not UP. It also returns the number of UP children. Checkers that report
+// several targets as nested results use it to aggregate them.
+func WorstOf(name string, children []*pb.CheckResult) (*pb.CheckResult, int) {
+	resp := &pb.CheckResult{
+		Name:      name,
+		Status:    pb.Status_STATUS_UP,
+		Timestamp: timestamppb.New(time.Now()),
+		Children:  children,
+	}
+
+	up := 0
+	for _, child := range children {
+		switch child.Status {
+		case pb.Status_STATUS_UP:
+			up++
+		case pb.Status_STATUS_DOWN:
+			resp.Status = pb.Status_STATUS_DOWN
+		default:
+			if resp.Status != pb.Status_STATUS_DOWN {
+				resp.Status = pb.Status_STATUS_DEGRADED
+			}
+		}
+	}
+	return resp, up
+}
+
+func (g *GroupChecker) Name() string {
+	return g.name
+}
+
+func (g *GroupChecker) MustOK(_ context.Context) bool {
+	return g.must
+}
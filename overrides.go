@@ -0,0 +1,126 @@
+package srvmon
+
+import (
+	"sort"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SetOverride forces the status of the named check until ttl elapses or the
+// override is cleared; a zero ttl never expires. Overridden checks are not
+// executed. An empty check targets service readiness: UP forces the service
+// ready, any other status makes it NOT READY so it drains without restarts.
+func (m *SrvMon) SetOverride(check string, status pb.Status, reason string, ttl time.Duration) *pb.Override {
+	return m.putOverride(&pb.Override{
+		Check:  check,
+		Status: status,
+		Reason: reason,
+	}, ttl)
+}
+
+// SetMaintenance marks the named check as in maintenance: it reports DEGRADED
+// with the given reason instead of being executed.
+func (m *SrvMon) SetMaintenance(check, reason string, ttl time.Duration) *pb.Override {
+	return m.putOverride(&pb.Override{
+		Check:       check,
+		Status:      pb.Status_STATUS_DEGRADED,
+		Reason:      reason,
+		Maintenance: true,
+	}, ttl)
+}
+
+// ClearOverride removes the override of the named check.
+// It reports whether an active override was removed.
+func (m *SrvMon) ClearOverride(check string) bool {
+	m.overridesMu.Lock()
+	defer m.overridesMu.Unlock()
+
+	m.expireOverrides()
+	if _, ok := m.overrides[check]; !ok {
+		return false
+	}
+	delete(m.overrides, check)
+	m.log.Info("override cleared", zap.String("check", check))
+	return true
+}
+
+func (m *SrvMon) putOverride(o *pb.Override, ttl time.Duration) *pb.Override {
+	if ttl > 0 {
+		o.ExpiresAt = timestamppb.New(time.Now().Add(ttl))
+	}
+
+	m.overridesMu.Lock()
+	defer m.overridesMu.Unlock()
+
+	if m.overrides == nil {
+		m.overrides = make(map[string]*pb.Override)
+	}
+	m.overrides[o.Check] = o
+
+	m.log.Info("override set",
+		zap.String("check", o.Check),
+		zap.Stringer("status", o.Status),
+		zap.String("reason", o.Reason),
+		zap.Bool("maintenance", o.Maintenance),
+		zap.Duration("ttl", ttl),
+	)
+
+	return proto.Clone(o).(*pb.Override)
+}
+
+// override returns a copy of the active override for check, or nil.
+func (m *SrvMon) override(check string) *pb.Override {
+	m.overridesMu.Lock()
+	defer m.overridesMu.Unlock()
+
+	m.expireOverrides()
+	o, ok := m.overrides[check]
+	if !ok {
+		return nil
+	}
+	return proto.Clone(o).(*pb.Override)
+}
+
+// activeOverrides returns copies of all active overrides ordered by check name.
+func (m *SrvMon) activeOverrides() []*pb.Override {
+	m.overridesMu.Lock()
+	defer m.overridesMu.Unlock()
+
+	m.expireOverrides()
+	list := make([]*pb.Override, 0, len(m.overrides))
+	for _, o := range m.overrides {
+		list = append(list, proto.Clone(o).(*pb.Override))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Check < list[j].Check })
+	return list
+}
+
+// expireOverrides drops overrides past their expiry. Callers hold overridesMu.
+func (m *SrvMon) expireOverrides() {
+	now := time.Now()
+	for check, o := range m.overrides {
+		if o.ExpiresAt != nil && !now.Before(o.ExpiresAt.AsTime()) {
+			delete(m.overrides, check)
+			m.log.Info("override expired", zap.String("check", check))
+		}
+	}
+}
+
+// overrideResult builds the result reported in place of an overridden check.
+func overrideResult(name string, o *pb.Override) *pb.CheckResult {
+	prefix := "override: "
+	if o.Maintenance {
+		prefix = "maintenance: "
+	}
+	return &pb.CheckResult{
+		Name:      name,
+		Status:    o.Status,
+		Message:   prefix + o.Reason,
+		Timestamp: timestamppb.New(time.Now()),
+		Override:  o,
+	}
+}
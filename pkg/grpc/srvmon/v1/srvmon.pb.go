@@ -9,6 +9,7 @@ package v1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -90,7 +91,23 @@ type CheckResult struct {
 	// timestamp is when the check was performed.
 	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	// error contains the error message if the check failed.
-	Error         string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// children contains nested results of composite checkers.
+	Children []*CheckResult `protobuf:"bytes,6,rep,name=children,proto3" json:"children,omitempty"`
+	// override is set when the status was forced through the admin API.
+	Override *Override `protobuf:"bytes,7,opt,name=override,proto3" json:"override,omitempty"`
+	// cached is true when the result was served from the result cache.
+	Cached bool `protobuf:"varint,8,opt,name=cached,proto3" json:"cached,omitempty"`
+	// age is how long ago a cached result was produced.
+	Age *durationpb.Duration `protobuf:"bytes,9,opt,name=age,proto3" json:"age,omitempty"`
+	// details holds checker-specific observations such as addresses and latencies.
+	Details map[string]string `protobuf:"bytes,10,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// duration is how long the check took to execute.
+	Duration *durationpb.Duration `protobuf:"bytes,11,opt,name=duration,proto3" json:"duration,omitempty"`
+	// description is the human-readable purpose of the check, if the checker declares one.
+	Description string `protobuf:"bytes,12,opt,name=description,proto3" json:"description,omitempty"`
+	// tags are labels the checker declares, such as "database" or "critical-path".
+	Tags          []string `protobuf:"bytes,13,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -160,6 +177,144 @@ func (x *CheckResult) GetError() string {
 	return ""
 }
 
+func (x *CheckResult) GetChildren() []*CheckResult {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+func (x *CheckResult) GetOverride() *Override {
+	if x != nil {
+		return x.Override
+	}
+	return nil
+}
+
+func (x *CheckResult) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *CheckResult) GetAge() *durationpb.Duration {
+	if x != nil {
+		return x.Age
+	}
+	return nil
+}
+
+func (x *CheckResult) GetDetails() map[string]string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *CheckResult) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *CheckResult) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CheckResult) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// Override is a manual status override of a check or of service readiness.
+type Override struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// check is the name of the overridden check. Empty means service readiness.
+	Check string `protobuf:"bytes,1,opt,name=check,proto3" json:"check,omitempty"`
+	// status is the forced status.
+	Status Status `protobuf:"varint,2,opt,name=status,proto3,enum=srvmon.v1.Status" json:"status,omitempty"`
+	// reason explains why the override was set.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// maintenance indicates the override was set through SetMaintenance.
+	Maintenance bool `protobuf:"varint,4,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	// expires_at is when the override is removed automatically. Unset means never.
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Override) Reset() {
+	*x = Override{}
+	mi := &file_v1_srvmon_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Override) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Override) ProtoMessage() {}
+
+func (x *Override) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_srvmon_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Override.ProtoReflect.Descriptor instead.
+func (*Override) Descriptor() ([]byte, []int) {
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Override) GetCheck() string {
+	if x != nil {
+		return x.Check
+	}
+	return ""
+}
+
+func (x *Override) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *Override) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Override) GetMaintenance() bool {
+	if x != nil {
+		return x.Maintenance
+	}
+	return false
+}
+
+func (x *Override) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
 // HealthRequest is the request for the Health RPC.
 type HealthRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -169,7 +324,7 @@ type HealthRequest struct {
 
 func (x *HealthRequest) Reset() {
 	*x = HealthRequest{}
-	mi := &file_v1_srvmon_proto_msgTypes[1]
+	mi := &file_v1_srvmon_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -181,7 +336,7 @@ func (x *HealthRequest) String() string {
 func (*HealthRequest) ProtoMessage() {}
 
 func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_v1_srvmon_proto_msgTypes[1]
+	mi := &file_v1_srvmon_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -194,7 +349,7 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
 func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_v1_srvmon_proto_rawDescGZIP(), []int{1}
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{2}
 }
 
 // HealthResponse is the response from the Health RPC.
@@ -207,14 +362,16 @@ type HealthResponse struct {
 	// checks contains individual check results.
 	Checks []*CheckResult `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty"`
 	// timestamp is when the report was generated.
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// overrides lists the active manual overrides.
+	Overrides     []*Override `protobuf:"bytes,5,rep,name=overrides,proto3" json:"overrides,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HealthResponse) Reset() {
 	*x = HealthResponse{}
-	mi := &file_v1_srvmon_proto_msgTypes[2]
+	mi := &file_v1_srvmon_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -226,7 +383,7 @@ func (x *HealthResponse) String() string {
 func (*HealthResponse) ProtoMessage() {}
 
 func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_v1_srvmon_proto_msgTypes[2]
+	mi := &file_v1_srvmon_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -239,7 +396,7 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
 func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_v1_srvmon_proto_rawDescGZIP(), []int{2}
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *HealthResponse) GetStatus() Status {
@@ -270,6 +427,13 @@ func (x *HealthResponse) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *HealthResponse) GetOverrides() []*Override {
+	if x != nil {
+		return x.Overrides
+	}
+	return nil
+}
+
 // ReadinessRequest is the request for the Readiness RPC.
 type ReadinessRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -279,7 +443,7 @@ type ReadinessRequest struct {
 
 func (x *ReadinessRequest) Reset() {
 	*x = ReadinessRequest{}
-	mi := &file_v1_srvmon_proto_msgTypes[3]
+	mi := &file_v1_srvmon_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -291,7 +455,7 @@ func (x *ReadinessRequest) String() string {
 func (*ReadinessRequest) ProtoMessage() {}
 
 func (x *ReadinessRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_v1_srvmon_proto_msgTypes[3]
+	mi := &file_v1_srvmon_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -304,7 +468,7 @@ func (x *ReadinessRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReadinessRequest.ProtoReflect.Descriptor instead.
 func (*ReadinessRequest) Descriptor() ([]byte, []int) {
-	return file_v1_srvmon_proto_rawDescGZIP(), []int{3}
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{4}
 }
 
 // ReadinessResponse is the response from the Readiness RPC.
@@ -317,14 +481,16 @@ type ReadinessResponse struct {
 	// checks contains individual readiness check results.
 	Checks []*CheckResult `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty"`
 	// timestamp is when the report was generated.
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// overrides lists the active manual overrides.
+	Overrides     []*Override `protobuf:"bytes,5,rep,name=overrides,proto3" json:"overrides,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ReadinessResponse) Reset() {
 	*x = ReadinessResponse{}
-	mi := &file_v1_srvmon_proto_msgTypes[4]
+	mi := &file_v1_srvmon_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -336,7 +502,7 @@ func (x *ReadinessResponse) String() string {
 func (*ReadinessResponse) ProtoMessage() {}
 
 func (x *ReadinessResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_v1_srvmon_proto_msgTypes[4]
+	mi := &file_v1_srvmon_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -349,7 +515,7 @@ func (x *ReadinessResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReadinessResponse.ProtoReflect.Descriptor instead.
 func (*ReadinessResponse) Descriptor() ([]byte, []int) {
-	return file_v1_srvmon_proto_rawDescGZIP(), []int{4}
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ReadinessResponse) GetReady() bool {
@@ -380,38 +546,419 @@ func (x *ReadinessResponse) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *ReadinessResponse) GetOverrides() []*Override {
+	if x != nil {
+		return x.Overrides
+	}
+	return nil
+}
+
+// RunChecksRequest is the request for the RunChecks RPC.
+type RunChecksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// names selects the checks to run. Empty runs every check.
+	Names         []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunChecksRequest) Reset() {
+	*x = RunChecksRequest{}
+	mi := &file_v1_srvmon_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunChecksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunChecksRequest) ProtoMessage() {}
+
+func (x *RunChecksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_srvmon_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunChecksRequest.ProtoReflect.Descriptor instead.
+func (*RunChecksRequest) Descriptor() ([]byte, []int) {
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RunChecksRequest) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+// RunChecksResponse is the response from the RunChecks RPC.
+type RunChecksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// checks contains the fresh check results in the requested order.
+	Checks []*CheckResult `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+	// timestamp is when the report was generated.
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunChecksResponse) Reset() {
+	*x = RunChecksResponse{}
+	mi := &file_v1_srvmon_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunChecksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunChecksResponse) ProtoMessage() {}
+
+func (x *RunChecksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_srvmon_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunChecksResponse.ProtoReflect.Descriptor instead.
+func (*RunChecksResponse) Descriptor() ([]byte, []int) {
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RunChecksResponse) GetChecks() []*CheckResult {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+func (x *RunChecksResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// SetOverrideRequest is the request for the SetOverride RPC.
+type SetOverrideRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// check is the name of the check to override. Empty targets service readiness.
+	Check string `protobuf:"bytes,1,opt,name=check,proto3" json:"check,omitempty"`
+	// status is the status to report while the override is active.
+	Status Status `protobuf:"varint,2,opt,name=status,proto3,enum=srvmon.v1.Status" json:"status,omitempty"`
+	// reason explains why the override was set.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// ttl is how long the override stays active. Zero means until cleared.
+	Ttl           *durationpb.Duration `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOverrideRequest) Reset() {
+	*x = SetOverrideRequest{}
+	mi := &file_v1_srvmon_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOverrideRequest) ProtoMessage() {}
+
+func (x *SetOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_srvmon_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOverrideRequest.ProtoReflect.Descriptor instead.
+func (*SetOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetOverrideRequest) GetCheck() string {
+	if x != nil {
+		return x.Check
+	}
+	return ""
+}
+
+func (x *SetOverrideRequest) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *SetOverrideRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SetOverrideRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+// ClearOverrideRequest is the request for the ClearOverride RPC.
+type ClearOverrideRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// check is the name of the check. Empty targets service readiness.
+	Check         string `protobuf:"bytes,1,opt,name=check,proto3" json:"check,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearOverrideRequest) Reset() {
+	*x = ClearOverrideRequest{}
+	mi := &file_v1_srvmon_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOverrideRequest) ProtoMessage() {}
+
+func (x *ClearOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_srvmon_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOverrideRequest.ProtoReflect.Descriptor instead.
+func (*ClearOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ClearOverrideRequest) GetCheck() string {
+	if x != nil {
+		return x.Check
+	}
+	return ""
+}
+
+// ClearOverrideResponse is the response from the ClearOverride RPC.
+type ClearOverrideResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// cleared is false if no override was active.
+	Cleared       bool `protobuf:"varint,1,opt,name=cleared,proto3" json:"cleared,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearOverrideResponse) Reset() {
+	*x = ClearOverrideResponse{}
+	mi := &file_v1_srvmon_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearOverrideResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOverrideResponse) ProtoMessage() {}
+
+func (x *ClearOverrideResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_srvmon_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOverrideResponse.ProtoReflect.Descriptor instead.
+func (*ClearOverrideResponse) Descriptor() ([]byte, []int) {
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ClearOverrideResponse) GetCleared() bool {
+	if x != nil {
+		return x.Cleared
+	}
+	return false
+}
+
+// SetMaintenanceRequest is the request for the SetMaintenance RPC.
+type SetMaintenanceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// check is the name of the check under maintenance.
+	Check string `protobuf:"bytes,1,opt,name=check,proto3" json:"check,omitempty"`
+	// reason explains the maintenance.
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// ttl is how long the maintenance lasts. Zero means until cleared.
+	Ttl           *durationpb.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetMaintenanceRequest) Reset() {
+	*x = SetMaintenanceRequest{}
+	mi := &file_v1_srvmon_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetMaintenanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaintenanceRequest) ProtoMessage() {}
+
+func (x *SetMaintenanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_srvmon_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaintenanceRequest.ProtoReflect.Descriptor instead.
+func (*SetMaintenanceRequest) Descriptor() ([]byte, []int) {
+	return file_v1_srvmon_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SetMaintenanceRequest) GetCheck() string {
+	if x != nil {
+		return x.Check
+	}
+	return ""
+}
+
+func (x *SetMaintenanceRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SetMaintenanceRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
 var File_v1_srvmon_proto protoreflect.FileDescriptor
 
 const file_v1_srvmon_proto_rawDesc = "" +
 	"\n" +
-	"\x0fv1/srvmon.proto\x12\tsrvmon.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb6\x01\n" +
+	"\x0fv1/srvmon.proto\x12\tsrvmon.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc8\x04\n" +
 	"\vCheckResult\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12)\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x11.srvmon.v1.StatusR\x06status\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\x128\n" +
 	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error\"\x0f\n" +
-	"\rHealthRequest\"\xbf\x01\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x122\n" +
+	"\bchildren\x18\x06 \x03(\v2\x16.srvmon.v1.CheckResultR\bchildren\x12/\n" +
+	"\boverride\x18\a \x01(\v2\x13.srvmon.v1.OverrideR\boverride\x12\x16\n" +
+	"\x06cached\x18\b \x01(\bR\x06cached\x12+\n" +
+	"\x03age\x18\t \x01(\v2\x19.google.protobuf.DurationR\x03age\x12=\n" +
+	"\adetails\x18\n" +
+	" \x03(\v2#.srvmon.v1.CheckResult.DetailsEntryR\adetails\x125\n" +
+	"\bduration\x18\v \x01(\v2\x19.google.protobuf.DurationR\bduration\x12 \n" +
+	"\vdescription\x18\f \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\r \x03(\tR\x04tags\x1a:\n" +
+	"\fDetailsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc0\x01\n" +
+	"\bOverride\x12\x14\n" +
+	"\x05check\x18\x01 \x01(\tR\x05check\x12)\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x11.srvmon.v1.StatusR\x06status\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12 \n" +
+	"\vmaintenance\x18\x04 \x01(\bR\vmaintenance\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\x0f\n" +
+	"\rHealthRequest\"\xf2\x01\n" +
 	"\x0eHealthResponse\x12)\n" +
 	"\x06status\x18\x01 \x01(\x0e2\x11.srvmon.v1.StatusR\x06status\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12.\n" +
 	"\x06checks\x18\x03 \x03(\v2\x16.srvmon.v1.CheckResultR\x06checks\x128\n" +
-	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x12\n" +
-	"\x10ReadinessRequest\"\xab\x01\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x121\n" +
+	"\toverrides\x18\x05 \x03(\v2\x13.srvmon.v1.OverrideR\toverrides\"\x12\n" +
+	"\x10ReadinessRequest\"\xde\x01\n" +
 	"\x11ReadinessResponse\x12\x14\n" +
 	"\x05ready\x18\x01 \x01(\bR\x05ready\x12\x16\n" +
 	"\x06reason\x18\x02 \x01(\tR\x06reason\x12.\n" +
 	"\x06checks\x18\x03 \x03(\v2\x16.srvmon.v1.CheckResultR\x06checks\x128\n" +
-	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp*i\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x121\n" +
+	"\toverrides\x18\x05 \x03(\v2\x13.srvmon.v1.OverrideR\toverrides\"(\n" +
+	"\x10RunChecksRequest\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\"}\n" +
+	"\x11RunChecksResponse\x12.\n" +
+	"\x06checks\x18\x01 \x03(\v2\x16.srvmon.v1.CheckResultR\x06checks\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x9a\x01\n" +
+	"\x12SetOverrideRequest\x12\x14\n" +
+	"\x05check\x18\x01 \x01(\tR\x05check\x12)\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x11.srvmon.v1.StatusR\x06status\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12+\n" +
+	"\x03ttl\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\",\n" +
+	"\x14ClearOverrideRequest\x12\x14\n" +
+	"\x05check\x18\x01 \x01(\tR\x05check\"1\n" +
+	"\x15ClearOverrideResponse\x12\x18\n" +
+	"\acleared\x18\x01 \x01(\bR\acleared\"r\n" +
+	"\x15SetMaintenanceRequest\x12\x14\n" +
+	"\x05check\x18\x01 \x01(\tR\x05check\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12+\n" +
+	"\x03ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x03ttl*i\n" +
 	"\x06Status\x12\x16\n" +
 	"\x12STATUS_UNSPECIFIED\x10\x00\x12\r\n" +
 	"\tSTATUS_UP\x10\x01\x12\x0f\n" +
 	"\vSTATUS_DOWN\x10\x02\x12\x13\n" +
 	"\x0fSTATUS_DEGRADED\x10\x03\x12\x12\n" +
-	"\x0eSTATUS_UNKNOWN\x10\x042\x8b\x01\n" +
+	"\x0eSTATUS_UNKNOWN\x10\x042\xd3\x01\n" +
 	"\x06srvmon\x12=\n" +
 	"\x06Health\x12\x18.srvmon.v1.HealthRequest\x1a\x19.srvmon.v1.HealthResponse\x12B\n" +
-	"\x05Ready\x12\x1b.srvmon.v1.ReadinessRequest\x1a\x1c.srvmon.v1.ReadinessResponseB-Z+github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1b\x06proto3"
+	"\x05Ready\x12\x1b.srvmon.v1.ReadinessRequest\x1a\x1c.srvmon.v1.ReadinessResponse\x12F\n" +
+	"\tRunChecks\x12\x1b.srvmon.v1.RunChecksRequest\x1a\x1c.srvmon.v1.RunChecksResponse2\xe7\x01\n" +
+	"\x05admin\x12A\n" +
+	"\vSetOverride\x12\x1d.srvmon.v1.SetOverrideRequest\x1a\x13.srvmon.v1.Override\x12R\n" +
+	"\rClearOverride\x12\x1f.srvmon.v1.ClearOverrideRequest\x1a .srvmon.v1.ClearOverrideResponse\x12G\n" +
+	"\x0eSetMaintenance\x12 .srvmon.v1.SetMaintenanceRequest\x1a\x13.srvmon.v1.OverrideB-Z+github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1b\x06proto3"
 
 var (
 	file_v1_srvmon_proto_rawDescOnce sync.Once
@@ -426,33 +973,64 @@ func file_v1_srvmon_proto_rawDescGZIP() []byte {
 }
 
 var file_v1_srvmon_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_v1_srvmon_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_v1_srvmon_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_v1_srvmon_proto_goTypes = []any{
 	(Status)(0),                   // 0: srvmon.v1.Status
 	(*CheckResult)(nil),           // 1: srvmon.v1.CheckResult
-	(*HealthRequest)(nil),         // 2: srvmon.v1.HealthRequest
-	(*HealthResponse)(nil),        // 3: srvmon.v1.HealthResponse
-	(*ReadinessRequest)(nil),      // 4: srvmon.v1.ReadinessRequest
-	(*ReadinessResponse)(nil),     // 5: srvmon.v1.ReadinessResponse
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(*Override)(nil),              // 2: srvmon.v1.Override
+	(*HealthRequest)(nil),         // 3: srvmon.v1.HealthRequest
+	(*HealthResponse)(nil),        // 4: srvmon.v1.HealthResponse
+	(*ReadinessRequest)(nil),      // 5: srvmon.v1.ReadinessRequest
+	(*ReadinessResponse)(nil),     // 6: srvmon.v1.ReadinessResponse
+	(*RunChecksRequest)(nil),      // 7: srvmon.v1.RunChecksRequest
+	(*RunChecksResponse)(nil),     // 8: srvmon.v1.RunChecksResponse
+	(*SetOverrideRequest)(nil),    // 9: srvmon.v1.SetOverrideRequest
+	(*ClearOverrideRequest)(nil),  // 10: srvmon.v1.ClearOverrideRequest
+	(*ClearOverrideResponse)(nil), // 11: srvmon.v1.ClearOverrideResponse
+	(*SetMaintenanceRequest)(nil), // 12: srvmon.v1.SetMaintenanceRequest
+	nil,                           // 13: srvmon.v1.CheckResult.DetailsEntry
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 15: google.protobuf.Duration
 }
 var file_v1_srvmon_proto_depIdxs = []int32{
-	0, // 0: srvmon.v1.CheckResult.status:type_name -> srvmon.v1.Status
-	6, // 1: srvmon.v1.CheckResult.timestamp:type_name -> google.protobuf.Timestamp
-	0, // 2: srvmon.v1.HealthResponse.status:type_name -> srvmon.v1.Status
-	1, // 3: srvmon.v1.HealthResponse.checks:type_name -> srvmon.v1.CheckResult
-	6, // 4: srvmon.v1.HealthResponse.timestamp:type_name -> google.protobuf.Timestamp
-	1, // 5: srvmon.v1.ReadinessResponse.checks:type_name -> srvmon.v1.CheckResult
-	6, // 6: srvmon.v1.ReadinessResponse.timestamp:type_name -> google.protobuf.Timestamp
-	2, // 7: srvmon.v1.srvmon.Health:input_type -> srvmon.v1.HealthRequest
-	4, // 8: srvmon.v1.srvmon.Ready:input_type -> srvmon.v1.ReadinessRequest
-	3, // 9: srvmon.v1.srvmon.Health:output_type -> srvmon.v1.HealthResponse
-	5, // 10: srvmon.v1.srvmon.Ready:output_type -> srvmon.v1.ReadinessResponse
-	9, // [9:11] is the sub-list for method output_type
-	7, // [7:9] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	0,  // 0: srvmon.v1.CheckResult.status:type_name -> srvmon.v1.Status
+	14, // 1: srvmon.v1.CheckResult.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 2: srvmon.v1.CheckResult.children:type_name -> srvmon.v1.CheckResult
+	2,  // 3: srvmon.v1.CheckResult.override:type_name -> srvmon.v1.Override
+	15, // 4: srvmon.v1.CheckResult.age:type_name -> google.protobuf.Duration
+	13, // 5: srvmon.v1.CheckResult.details:type_name -> srvmon.v1.CheckResult.DetailsEntry
+	15, // 6: srvmon.v1.CheckResult.duration:type_name -> google.protobuf.Duration
+	0,  // 7: srvmon.v1.Override.status:type_name -> srvmon.v1.Status
+	14, // 8: srvmon.v1.Override.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 9: srvmon.v1.HealthResponse.status:type_name -> srvmon.v1.Status
+	1,  // 10: srvmon.v1.HealthResponse.checks:type_name -> srvmon.v1.CheckResult
+	14, // 11: srvmon.v1.HealthResponse.timestamp:type_name -> google.protobuf.Timestamp
+	2,  // 12: srvmon.v1.HealthResponse.overrides:type_name -> srvmon.v1.Override
+	1,  // 13: srvmon.v1.ReadinessResponse.checks:type_name -> srvmon.v1.CheckResult
+	14, // 14: srvmon.v1.ReadinessResponse.timestamp:type_name -> google.protobuf.Timestamp
+	2,  // 15: srvmon.v1.ReadinessResponse.overrides:type_name -> srvmon.v1.Override
+	1,  // 16: srvmon.v1.RunChecksResponse.checks:type_name -> srvmon.v1.CheckResult
+	14, // 17: srvmon.v1.RunChecksResponse.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 18: srvmon.v1.SetOverrideRequest.status:type_name -> srvmon.v1.Status
+	15, // 19: srvmon.v1.SetOverrideRequest.ttl:type_name -> google.protobuf.Duration
+	15, // 20: srvmon.v1.SetMaintenanceRequest.ttl:type_name -> google.protobuf.Duration
+	3,  // 21: srvmon.v1.srvmon.Health:input_type -> srvmon.v1.HealthRequest
+	5,  // 22: srvmon.v1.srvmon.Ready:input_type -> srvmon.v1.ReadinessRequest
+	7,  // 23: srvmon.v1.srvmon.RunChecks:input_type -> srvmon.v1.RunChecksRequest
+	9,  // 24: srvmon.v1.admin.SetOverride:input_type -> srvmon.v1.SetOverrideRequest
+	10, // 25: srvmon.v1.admin.ClearOverride:input_type -> srvmon.v1.ClearOverrideRequest
+	12, // 26: srvmon.v1.admin.SetMaintenance:input_type -> srvmon.v1.SetMaintenanceRequest
+	4,  // 27: srvmon.v1.srvmon.Health:output_type -> srvmon.v1.HealthResponse
+	6,  // 28: srvmon.v1.srvmon.Ready:output_type -> srvmon.v1.ReadinessResponse
+	8,  // 29: srvmon.v1.srvmon.RunChecks:output_type -> srvmon.v1.RunChecksResponse
+	2,  // 30: srvmon.v1.admin.SetOverride:output_type -> srvmon.v1.Override
+	11, // 31: srvmon.v1.admin.ClearOverride:output_type -> srvmon.v1.ClearOverrideResponse
+	2,  // 32: srvmon.v1.admin.SetMaintenance:output_type -> srvmon.v1.Override
+	27, // [27:33] is the sub-list for method output_type
+	21, // [21:27] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_v1_srvmon_proto_init() }
@@ -466,9 +1044,9 @@ func file_v1_srvmon_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_v1_srvmon_proto_rawDesc), len(file_v1_srvmon_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   5,
+			NumMessages:   13,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_v1_srvmon_proto_goTypes,
 		DependencyIndexes: file_v1_srvmon_proto_depIdxs,
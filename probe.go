@@ -0,0 +1,126 @@
+package srvmon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxCheckDuration bounds a shared execution whose callers set no deadline.
+const maxCheckDuration = 30 * time.Second
+
+// probe coalesces concurrent executions of one dependency so that callers
+// arriving while a check is in flight share its result, and keeps the
+// latest result for callers that accept a cached one.
+type probe struct {
+	mu       sync.Mutex
+	inflight *call
+	last     *pb.CheckResult
+	at       time.Time
+}
+
+// SetResultTTL lets Health and Ready reuse the result of the named checker for
+// up to ttl instead of executing it on every probe. Cached results are marked
+// with cached and age. RunChecks always executes. A zero ttl disables caching.
+// It takes precedence over the interval an Intervaled checker declares.
+func (m *SrvMon) SetResultTTL(name string, ttl time.Duration) *SrvMon {
+	if m.ttls == nil {
+		m.ttls = make(map[string]time.Duration)
+	}
+	m.ttls[name] = ttl
+	return m
+}
+
+type call struct {
+	done   chan struct{}
+	result *pb.CheckResult
+	err    error
+}
+
+// run returns the latest result if it is younger than ttl, otherwise it
+// executes dep or joins the execution already in flight. A zero ttl always
+// gets a fresh result. The shared execution is detached from the caller's
+// cancellation so one impatient caller cannot fail the others; each caller
+// still stops waiting on its own ctx. The execution keeps the caller's
+// deadline, capped at maxCheckDuration, so a Check that blocks until its
+// context is done cannot stay in flight forever.
+//
+// A request forwarded by another srvmon instance may read the cache but runs
+// dep on its own: its trail can make a SrvmonChecker report a loop that says
+// nothing about other callers, so its result is neither shared nor cached.
+func (p *probe) run(ctx context.Context, dep Checker, ttl time.Duration) (*pb.CheckResult, error) {
+	p.mu.Lock()
+	if age := time.Since(p.at); ttl > 0 && p.last != nil && age < ttl {
+		cached := proto.Clone(p.last).(*pb.CheckResult)
+		p.mu.Unlock()
+
+		cached.Cached = true
+		cached.Age = durationpb.New(age)
+		return cached, nil
+	}
+
+	if forwarded(ctx) {
+		p.mu.Unlock()
+		return runCheck(ctx, dep)
+	}
+
+	c := p.inflight
+	if c == nil {
+		c = &call{done: make(chan struct{})}
+		p.inflight = c
+		timeout := maxCheckDuration
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = min(time.Until(deadline), timeout)
+		}
+		execCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		go func() {
+			defer cancel()
+			p.execute(execCtx, dep, c)
+		}()
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.result, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// execute runs dep for the callers of c and keeps the result.
+func (p *probe) execute(ctx context.Context, dep Checker, c *call) {
+	c.result, c.err = runCheck(ctx, dep)
+
+	p.mu.Lock()
+	p.inflight = nil
+	if c.err == nil && c.result != nil {
+		p.last = c.result
+		p.at = time.Now()
+	}
+	p.mu.Unlock()
+
+	close(c.done)
+}
+
+// runCheck runs dep and completes its result with the timestamp, duration and
+// the metadata dep declares.
+func runCheck(ctx context.Context, dep Checker) (*pb.CheckResult, error) {
+	start := time.Now()
+	result, err := dep.Check(ctx)
+	if err == nil && result != nil {
+		if result.Timestamp == nil {
+			result.Timestamp = timestamppb.New(start)
+		}
+		if result.Duration == nil {
+			result.Duration = durationpb.New(time.Since(start))
+		}
+		describe(dep, result)
+	}
+	return result, err
+}
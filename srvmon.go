@@ -3,8 +3,10 @@ package srvmon
 import (
 	"context"
 	"errors"
+	"io"
 	"net"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -13,9 +15,11 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -41,13 +45,21 @@ type (
 	}
 
 	SrvMon struct {
+		id           string
 		dependencies []Checker
+		probes       []*probe
+		ttls         map[string]time.Duration
+		parents      map[string][]string
 		version      string
 		grpcAddr     string
 		httpAddr     string
+		adminToken   string
 
 		ready atomic.Bool
 
+		overridesMu sync.Mutex
+		overrides   map[string]*pb.Override
+
 		log *zap.Logger
 		pb.UnimplementedSrvmonServer
 	}
@@ -56,19 +68,22 @@ type (
 		Version     string `json:"version" yaml:"version" mapstructure:"version"`
 		GRPCAddress string `json:"grpc_address" yaml:"grpc_address" mapstructure:"grpc_address"`
 		HTTPAddress string `json:"http_address" yaml:"http_address" mapstructure:"http_address"`
+		AdminToken  string `json:"admin_token" yaml:"admin_token" mapstructure:"admin_token"`
 	}
 )
 
 func New(cfg Config, log *zap.Logger, dependencies ...Checker) *SrvMon {
 	m := &SrvMon{
-		version:  cfg.Version,
-		grpcAddr: cfg.GRPCAddress,
-		httpAddr: cfg.HTTPAddress,
-		log:      log,
+		id:         newInstanceID(),
+		version:    cfg.Version,
+		grpcAddr:   cfg.GRPCAddress,
+		httpAddr:   cfg.HTTPAddress,
+		adminToken: cfg.AdminToken,
+		log:        log,
 	}
 
 	if dependencies != nil {
-		m.dependencies = dependencies
+		m.AddDependencies(dependencies...)
 	}
 
 	return m
@@ -76,6 +91,9 @@ func New(cfg Config, log *zap.Logger, dependencies ...Checker) *SrvMon {
 
 func (m *SrvMon) AddDependencies(dependency ...Checker) *SrvMon {
 	m.dependencies = append(m.dependencies, dependency...)
+	for range dependency {
+		m.probes = append(m.probes, &probe{})
+	}
 	return m
 }
 
@@ -98,7 +116,7 @@ func (m *SrvMon) startREST() func(ctx context.Context) error {
 	router := mux.NewRouter()
 
 	healthHandler := func(w http.ResponseWriter, r *http.Request) {
-		resp, err := m.Health(r.Context(), &pb.HealthRequest{})
+		resp, err := m.Health(trailContext(r), &pb.HealthRequest{})
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusServiceUnavailable)
 			return
@@ -118,7 +136,7 @@ func (m *SrvMon) startREST() func(ctx context.Context) error {
 	}
 
 	readyHandler := func(w http.ResponseWriter, r *http.Request) {
-		resp, err := m.Ready(r.Context(), &pb.ReadinessRequest{})
+		resp, err := m.Ready(trailContext(r), &pb.ReadinessRequest{})
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusServiceUnavailable)
 			return
@@ -137,10 +155,60 @@ func (m *SrvMon) startREST() func(ctx context.Context) error {
 		}
 	}
 
+	runHandler := func(w http.ResponseWriter, r *http.Request) {
+		req := &pb.RunChecksRequest{}
+		if name, ok := mux.Vars(r)["name"]; ok {
+			req.Names = []string{name}
+		} else if !m.readProto(w, r, req) {
+			return
+		}
+
+		resp, err := m.RunChecks(trailContext(r), req)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				http.Error(w, status.Convert(err).Message(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		if len(mux.Vars(r)) > 0 {
+			m.writeProto(w, resp.Checks[0], nil)
+			return
+		}
+		m.writeProto(w, resp, nil)
+	}
+
+	graphHandler := func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Query().Get("format") {
+		case "", "dot":
+			w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+			_, err = io.WriteString(w, m.DOT())
+		case "mermaid":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, err = io.WriteString(w, m.Mermaid())
+		default:
+			http.Error(w, "unsupported format, use dot or mermaid", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			m.log.Error("write graph response", zap.Error(err))
+		}
+	}
+
 	router.HandleFunc("/health", healthHandler)
 	router.HandleFunc("/healthz", healthHandler)
 	router.HandleFunc("/ready", readyHandler)
 	router.HandleFunc("/readyz", readyHandler)
+	router.HandleFunc("/health/graph", graphHandler)
+	router.HandleFunc("/checks/run", runHandler).Methods(http.MethodPost)
+	router.HandleFunc("/checks/{name}/run", runHandler).Methods(http.MethodPost)
+
+	if m.adminToken != "" {
+		m.registerAdminREST(router)
+	}
 
 	srv := &http.Server{
 		Addr:              m.httpAddr,
@@ -181,9 +249,16 @@ func (m *SrvMon) startGRPC() func() {
 		grpc.MaxSendMsgSize(4 * 1024 * 1024),
 	}
 
+	if m.adminToken != "" {
+		opts = append(opts, grpc.ChainUnaryInterceptor(m.adminUnaryInterceptor))
+	}
+
 	s := grpc.NewServer(opts...)
 
 	pb.RegisterSrvmonServer(s, m)
+	if m.adminToken != "" {
+		pb.RegisterAdminServer(s, &adminServer{m: m})
+	}
 
 	healthSrv := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(s, healthSrv)
@@ -0,0 +1,255 @@
+package srvmon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// trailKey carries the IDs of the srvmon instances a health request has
+// already passed through. It travels as gRPC metadata and as an HTTP header.
+const trailKey = "x-srvmon-trail"
+
+const defaultMaxHops = 8
+
+type trailCtxKey struct{}
+
+// withTrail stores the trail to forward to downstream srvmon instances.
+func withTrail(ctx context.Context, trail []string) context.Context {
+	return context.WithValue(ctx, trailCtxKey{}, trail)
+}
+
+// trailFrom returns the trail set by withTrail, falling back to the trail
+// received in incoming gRPC metadata.
+func trailFrom(ctx context.Context) []string {
+	if trail, ok := ctx.Value(trailCtxKey{}).([]string); ok {
+		return trail
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		return parseTrail(strings.Join(md.Get(trailKey), ","))
+	}
+	return nil
+}
+
+// trailContext returns the request context carrying the trail from the HTTP header.
+func trailContext(r *http.Request) context.Context {
+	return withTrail(r.Context(), parseTrail(r.Header.Get(trailKey)))
+}
+
+func parseTrail(s string) []string {
+	var trail []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			trail = append(trail, id)
+		}
+	}
+	return trail
+}
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SrvmonChecker federates the health of a downstream service that embeds
+// srvmon by calling its Health endpoint over gRPC or HTTP/JSON. The remote
+// overall status becomes the status of this check.
+//
+// Every request carries the IDs of the srvmon instances it passed through, so
+// an instance reached again answers UNKNOWN instead of recursing, and chains
+// longer than the hop limit are not followed.
+type SrvmonChecker struct {
+	name string
+	must bool
+
+	client  pb.SrvmonClient
+	url     string
+	httpCli *http.Client
+
+	timeout  time.Duration
+	prefix   string
+	children bool
+	maxHops  int
+}
+
+type SrvmonCheckerOption func(*SrvmonChecker)
+
+// WithRemoteTimeout sets the deadline for the remote Health call.
+// Default: 3s.
+func WithRemoteTimeout(d time.Duration) SrvmonCheckerOption {
+	return func(c *SrvmonChecker) { c.timeout = d }
+}
+
+// WithRemoteChecks embeds the remote individual checks as nested children,
+// with their names prefixed by prefix.
+func WithRemoteChecks(prefix string) SrvmonCheckerOption {
+	return func(c *SrvmonChecker) {
+		c.children = true
+		c.prefix = prefix
+	}
+}
+
+// WithMaxHops limits how many srvmon instances a federated request may pass
+// through. Default: 8.
+func WithMaxHops(n int) SrvmonCheckerOption {
+	return func(c *SrvmonChecker) { c.maxHops = n }
+}
+
+// WithHTTPClient sets the client used by NewSrvmonHTTPChecker.
+func WithHTTPClient(cli *http.Client) SrvmonCheckerOption {
+	return func(c *SrvmonChecker) { c.httpCli = cli }
+}
+
+// NewSrvmonChecker calls srvmon.v1.srvmon/Health over conn.
+func NewSrvmonChecker(conn *grpc.ClientConn, name string, mustOK bool, opts ...SrvmonCheckerOption) *SrvmonChecker {
+	c := newSrvmonChecker(name, mustOK, opts)
+	c.client = pb.NewSrvmonClient(conn)
+	return c
+}
+
+// NewSrvmonHTTPChecker calls GET <baseURL>/health and decodes the JSON report.
+func NewSrvmonHTTPChecker(baseURL, name string, mustOK bool, opts ...SrvmonCheckerOption) *SrvmonChecker {
+	c := newSrvmonChecker(name, mustOK, opts)
+	c.url = strings.TrimSuffix(baseURL, "/") + "/health"
+	return c
+}
+
+func newSrvmonChecker(name string, mustOK bool, opts []SrvmonCheckerOption) *SrvmonChecker {
+	c := &SrvmonChecker{
+		name:    name,
+		must:    mustOK,
+		timeout: 3 * time.Second,
+		maxHops: defaultMaxHops,
+		httpCli: http.DefaultClient,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *SrvmonChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	resp := &pb.CheckResult{
+		Name:      c.name,
+		Timestamp: timestamppb.New(time.Now()),
+	}
+
+	trail := trailFrom(ctx)
+	if len(trail) >= c.maxHops {
+		resp.Status = pb.Status_STATUS_UNKNOWN
+		resp.Message = fmt.Sprintf("hop limit reached (%d)", c.maxHops)
+		return resp, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		hr  *pb.HealthResponse
+		err error
+	)
+	if c.client != nil {
+		if len(trail) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, trailKey, strings.Join(trail, ","))
+		}
+		hr, err = c.client.Health(ctx, &pb.HealthRequest{})
+	} else {
+		hr, err = c.fetch(ctx, trail)
+	}
+	if err != nil {
+		resp.Status = pb.Status_STATUS_DOWN
+		resp.Message = "remote health failed"
+		resp.Error = err.Error()
+		return resp, nil
+	}
+
+	switch hr.GetStatus() {
+	case pb.Status_STATUS_UP, pb.Status_STATUS_DEGRADED, pb.Status_STATUS_DOWN:
+		resp.Status = hr.GetStatus()
+	default:
+		resp.Status = pb.Status_STATUS_UNKNOWN
+	}
+	resp.Message = "remote " + strings.TrimPrefix(hr.GetStatus().String(), "STATUS_")
+	resp.Details = map[string]string{
+		"version": hr.GetVersion(),
+		"checks":  fmt.Sprint(len(hr.GetChecks())),
+	}
+
+	if c.children {
+		for _, check := range hr.GetChecks() {
+			check.Name = c.prefix + check.Name
+			resp.Children = append(resp.Children, check)
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *SrvmonChecker) fetch(ctx context.Context, trail []string) (*pb.HealthResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(trail) > 0 {
+		req.Header.Set(trailKey, strings.Join(trail, ","))
+	}
+
+	httpResp, err := c.httpCli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, 4*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", httpResp.Status, strings.TrimSpace(string(body)))
+	}
+
+	hr := &pb.HealthResponse{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, hr); err != nil {
+		return nil, err
+	}
+	return hr, nil
+}
+
+func (c *SrvmonChecker) Name() string {
+	return c.name
+}
+
+func (c *SrvmonChecker) MustOK(_ context.Context) bool {
+	return c.must
+}
+
+// forwarded reports whether ctx, after enterTrail, carries a trail from
+// another srvmon instance.
+func forwarded(ctx context.Context) bool {
+	return len(trailFrom(ctx)) > 1
+}
+
+// enterTrail appends this instance to the incoming trail. It reports false
+// when the request already passed through this instance.
+func (m *SrvMon) enterTrail(ctx context.Context) (context.Context, bool) {
+	trail := trailFrom(ctx)
+	if slices.Contains(trail, m.id) {
+		return ctx, false
+	}
+	return withTrail(ctx, append(slices.Clip(trail), m.id)), true
+}
@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type stubChecker struct {
+	name  string
+	calls int
+}
+
+func (c *stubChecker) Name() string { return c.name }
+
+func (c *stubChecker) MustOK(_ context.Context) bool { return true }
+
+func (c *stubChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	c.calls++
+	return &pb.CheckResult{Name: c.name, Status: pb.Status_STATUS_DOWN, Timestamp: timestamppb.Now()}, nil
+}
+
+func TestMaintenance(t *testing.T) {
+	pg := &stubChecker{name: "postgres"}
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), pg)
+	m.SetMaintenance("postgres", "vacuum full", time.Hour)
+
+	resp, err := m.Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := resp.Checks[0]
+	if pg.calls != 0 {
+		t.Fatal("checker under maintenance was executed")
+	}
+	if check.Status != pb.Status_STATUS_DEGRADED || check.Message != "maintenance: vacuum full" {
+		t.Fatalf("got %s %q", check.Status, check.Message)
+	}
+	if !check.GetOverride().GetMaintenance() || len(resp.Overrides) != 1 {
+		t.Fatalf("override not visible: %v", resp)
+	}
+	if resp.Status != pb.Status_STATUS_UP {
+		t.Fatalf("overall status: got %s", resp.Status)
+	}
+
+	if !m.ClearOverride("postgres") {
+		t.Fatal("override not cleared")
+	}
+	if resp, _ = m.Health(context.Background(), &pb.HealthRequest{}); resp.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("after clear: got %s", resp.Status)
+	}
+}
+
+func TestOverrideExpires(t *testing.T) {
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), &stubChecker{name: "cache"})
+	m.SetOverride("cache", pb.Status_STATUS_UP, "known flake", 20*time.Millisecond)
+
+	resp, _ := m.Health(context.Background(), &pb.HealthRequest{})
+	if resp.Checks[0].Status != pb.Status_STATUS_UP {
+		t.Fatalf("override not applied: %s", resp.Checks[0].Status)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	resp, _ = m.Health(context.Background(), &pb.HealthRequest{})
+	if resp.Checks[0].Status != pb.Status_STATUS_DOWN || len(resp.Overrides) != 0 {
+		t.Fatalf("override did not expire: %v", resp)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	m := srvmon.New(srvmon.Config{}, zap.NewNop())
+	m.SetReady()
+	m.SetOverride("", pb.Status_STATUS_DOWN, "draining for upgrade", 0)
+
+	resp, err := m.Ready(context.Background(), &pb.ReadinessRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Ready || resp.Reason != "override: draining for upgrade" {
+		t.Fatalf("got ready=%v reason=%q", resp.Ready, resp.Reason)
+	}
+
+	health, _ := m.Health(context.Background(), &pb.HealthRequest{})
+	if health.Status != pb.Status_STATUS_UP {
+		t.Fatalf("liveness affected by drain: %s", health.Status)
+	}
+}
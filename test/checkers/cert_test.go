@@ -0,0 +1,198 @@
+package checkers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// testCA is a self-signed CA that issues leaf certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue creates a leaf certificate for localhost valid for the given duration.
+func (ca *testCA) issue(t *testing.T, validFor time.Duration) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der, ca.cert.Raw}, PrivateKey: key, Leaf: leaf}
+}
+
+// serveTLS accepts TLS connections presenting cert and returns the address.
+func serveTLS(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = conn.(*tls.Conn).Handshake()
+				_ = conn.Close()
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestCertCheckerWindows(t *testing.T) {
+	ca := newTestCA(t)
+
+	tests := []struct {
+		name     string
+		validFor time.Duration
+		want     pb.Status
+	}{
+		{"valid", 90 * 24 * time.Hour, pb.Status_STATUS_UP},
+		{"warning", 7 * 24 * time.Hour, pb.Status_STATUS_DEGRADED},
+		{"critical", 24 * time.Hour, pb.Status_STATUS_DOWN},
+		{"expired", -time.Hour, pb.Status_STATUS_DOWN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := serveTLS(t, ca.issue(t, tt.validFor))
+			res := check(t, checkers.NewTLSCertChecker("cert", addr, true,
+				checkers.WithCertRootCAs(ca.pool)))
+			if res.Status != tt.want {
+				t.Fatalf("got %s, want %s: %s %s", res.Status, tt.want, res.Message, res.Error)
+			}
+		})
+	}
+}
+
+func TestCertCheckerDetails(t *testing.T) {
+	ca := newTestCA(t)
+	addr := serveTLS(t, ca.issue(t, 90*24*time.Hour))
+
+	res := check(t, checkers.NewTLSCertChecker("cert", addr, true,
+		checkers.WithCertRootCAs(ca.pool), checkers.WithCertServerName("localhost")))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+	for key, want := range map[string]string{
+		"subject":           "CN=localhost",
+		"sans":              "localhost,127.0.0.1",
+		"chain_verified":    "true",
+		"hostname_verified": "true",
+		"chain.1.subject":   "CN=test-ca",
+	} {
+		if res.Details[key] != want {
+			t.Errorf("details[%s] = %q, want %q", key, res.Details[key], want)
+		}
+	}
+	if res.Details["not_after"] == "" || res.Details["chain.1.not_after"] == "" || res.Details["tls_version"] == "" {
+		t.Fatalf("missing details: %v", res.Details)
+	}
+}
+
+func TestCertCheckerVerification(t *testing.T) {
+	ca := newTestCA(t)
+	addr := serveTLS(t, ca.issue(t, 90*24*time.Hour))
+
+	res := check(t, checkers.NewTLSCertChecker("cert", addr, true,
+		checkers.WithCertRootCAs(ca.pool), checkers.WithCertServerName("example.com")))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["hostname_verified"] != "false" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+
+	// Untrusted chain, judged on expiry only.
+	res = check(t, checkers.NewTLSCertChecker("cert", addr, true, checkers.WithExpiryOnly()))
+	if res.Status != pb.Status_STATUS_UP || res.Details["chain_verified"] != "false" || res.Error == "" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+}
+
+func TestCertFileChecker(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, 7*24*time.Hour)
+
+	var bundle strings.Builder
+	for _, der := range cert.Certificate {
+		_ = pem.Encode(&bundle, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	path := filepath.Join(t.TempDir(), "bundle.pem")
+	if err := os.WriteFile(path, []byte(bundle.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	res := check(t, checkers.NewCertFileChecker("cert", path, true, checkers.WithCertRootCAs(ca.pool)))
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["hostname_verified"] != "skipped" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+
+	res = check(t, checkers.NewCertFileChecker("cert", path, true,
+		checkers.WithExpiryWindows(3*24*time.Hour, 24*time.Hour), checkers.WithCertRootCAs(ca.pool)))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewCertFileChecker("cert", filepath.Join(t.TempDir(), "missing.pem"), true))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+}
@@ -0,0 +1,160 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// check runs c and fails the test if Check returns an error, which the
+// checkers never do.
+func check(t *testing.T, c interface {
+	Check(ctx context.Context) (*pb.CheckResult, error)
+}) *pb.CheckResult {
+	t.Helper()
+	res, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func listenTCP(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+// closedTCP returns an address nobody listens on.
+func closedTCP(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	_ = lis.Close()
+	return addr
+}
+
+func TestTCPChecker(t *testing.T) {
+	addr := listenTCP(t)
+
+	res := check(t, checkers.NewTCPChecker("tcp", addr, true))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+	if res.Details["address"] != addr || res.Details["latency"] == "" {
+		t.Fatalf("missing details: %v", res.Details)
+	}
+
+	res = check(t, checkers.NewTCPChecker("tcp", closedTCP(t), true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error == "" {
+		t.Fatalf("got %s, want DOWN with error", res.Status)
+	}
+}
+
+func TestTCPCheckerSourceAddr(t *testing.T) {
+	res := check(t, checkers.NewTCPChecker("tcp", listenTCP(t), true,
+		checkers.WithSourceAddr(net.ParseIP("127.0.0.1")),
+	))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestTCPCheckerQuorum(t *testing.T) {
+	up, down := listenTCP(t), closedTCP(t)
+	_, upPort, _ := net.SplitHostPort(up)
+	_, downPort, _ := net.SplitHostPort(down)
+
+	res := check(t, checkers.NewTCPChecker("tcp", net.JoinHostPort("127.0.0.1", upPort), true,
+		checkers.WithReachable(1),
+	))
+	if res.Status != pb.Status_STATUS_UP || res.Details["reachable"] != "1/1" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+
+	res = check(t, checkers.NewTCPChecker("tcp", net.JoinHostPort("127.0.0.1", downPort), true,
+		checkers.WithReachable(1),
+	))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["reachable"] != "0/1" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+}
+
+func TestUnixChecker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "srvmon.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lis.Close() }()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	res := check(t, checkers.NewUnixChecker("unix", path, true))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewUnixChecker("unix", path+".missing", true))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+}
+
+func TestUDPChecker(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = pc.Close() }()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, from, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = pc.WriteTo(bytes.ToUpper(buf[:n]), from)
+		}
+	}()
+
+	addr := pc.LocalAddr().String()
+
+	res := check(t, checkers.NewUDPChecker("udp", addr, []byte("ping"), []byte("PING"), true))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewUDPChecker("udp", addr, []byte("ping"), []byte("pong"), true))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+}
@@ -0,0 +1,103 @@
+//go:build linux
+
+package checkers
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// noLimits keeps the outcome independent of how full the test machine is.
+var noLimits = []checkers.DiskOption{
+	checkers.WithMinFreePercent(0, 0),
+	checkers.WithMinFreeInodesPercent(0, 0),
+}
+
+func TestDiskCheckerCanary(t *testing.T) {
+	dir := t.TempDir()
+
+	res := check(t, checkers.NewDiskChecker("data", []string{dir}, true,
+		append(noLimits, checkers.WithWriteCanary())...))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	for _, key := range []string{"total_bytes", "free_bytes", "free_percent", "canary_latency"} {
+		if res.Details[key] == "" {
+			t.Errorf("missing %s: %v", key, res.Details)
+		}
+	}
+	if res.Details["read_only"] != "false" {
+		t.Errorf("read_only = %q", res.Details["read_only"])
+	}
+
+	left, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("canary left behind: %v", left)
+	}
+}
+
+func TestDiskCheckerThresholds(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		name string
+		opts []checkers.DiskOption
+		want pb.Status
+	}{
+		{"percent warning", []checkers.DiskOption{checkers.WithMinFreePercent(101, 0)}, pb.Status_STATUS_DEGRADED},
+		{"percent critical", []checkers.DiskOption{checkers.WithMinFreePercent(101, 101)}, pb.Status_STATUS_DOWN},
+		{"bytes warning", []checkers.DiskOption{checkers.WithMinFreeBytes(math.MaxUint64, 0)}, pb.Status_STATUS_DEGRADED},
+		{"bytes critical", []checkers.DiskOption{checkers.WithMinFreeBytes(0, math.MaxUint64)}, pb.Status_STATUS_DOWN},
+		{"inodes critical", []checkers.DiskOption{checkers.WithMinFreeInodesPercent(101, 101)}, pb.Status_STATUS_DOWN},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append(append([]checkers.DiskOption(nil), noLimits...), tc.opts...)
+			res := check(t, checkers.NewDiskChecker("data", []string{dir}, true, opts...))
+			if res.Status != tc.want {
+				t.Fatalf("got %s: %s", res.Status, res.Message)
+			}
+		})
+	}
+}
+
+func TestDiskCheckerPaths(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	res := check(t, checkers.NewDiskChecker("volumes", []string{t.TempDir(), t.TempDir(), missing}, true, noLimits...))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "2/3 paths healthy" || len(res.Children) != 3 {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+	if child := res.Children[2]; child.Name != missing || child.Message != "statfs failed" {
+		t.Errorf("got child %s: %s", child.Name, child.Message)
+	}
+}
+
+func TestDiskCheckerNoPaths(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("empty path list accepted")
+		}
+	}()
+	checkers.NewDiskChecker("disk", nil, true)
+}
+
+func TestDiskCheckerUnwritable(t *testing.T) {
+	// procfs reports no blocks, so only the canary can fail it.
+	res := check(t, checkers.NewDiskChecker("proc", []string{"/proc"}, true))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	res = check(t, checkers.NewDiskChecker("proc", []string{"/proc"}, true, checkers.WithWriteCanary()))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "write canary failed" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
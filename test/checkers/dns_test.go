@@ -0,0 +1,188 @@
+package checkers
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// stubDNS answers UDP queries from a fixed zone. Names not in the zone get
+// NXDOMAIN; known names without records of the queried type get no answers.
+type stubDNS struct {
+	addr string
+
+	mu   sync.Mutex
+	zone map[string][]dnsmessage.ResourceBody
+}
+
+func newStubDNS(t *testing.T) *stubDNS {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = pc.Close() })
+
+	s := &stubDNS{addr: pc.LocalAddr().String(), zone: make(map[string][]dnsmessage.ResourceBody)}
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply, err := s.answer(buf[:n]); err == nil {
+				_, _ = pc.WriteTo(reply, from)
+			}
+		}
+	}()
+	return s
+}
+
+// set replaces the records of name, a fully qualified name like "api.test.".
+func (s *stubDNS) set(name string, rrs ...dnsmessage.ResourceBody) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.zone[name] = rrs
+}
+
+func (s *stubDNS) answer(query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	rrs, ok := s.zone[q.Name.String()]
+	s.mu.Unlock()
+
+	rh := dnsmessage.Header{ID: h.ID, Response: true, Authoritative: true, RecursionAvailable: true}
+	if !ok {
+		rh.RCode = dnsmessage.RCodeNameError
+	}
+	b := dnsmessage.NewBuilder(nil, rh)
+	b.EnableCompression()
+	_ = b.StartQuestions()
+	_ = b.Question(q)
+	_ = b.StartAnswers()
+
+	for _, rr := range rrs {
+		hdr := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60}
+		switch body := rr.(type) {
+		case *dnsmessage.AResource:
+			if q.Type == dnsmessage.TypeA {
+				err = b.AResource(hdr, *body)
+			}
+		case *dnsmessage.AAAAResource:
+			if q.Type == dnsmessage.TypeAAAA {
+				err = b.AAAAResource(hdr, *body)
+			}
+		case *dnsmessage.CNAMEResource:
+			err = b.CNAMEResource(hdr, *body)
+		case *dnsmessage.SRVResource:
+			if q.Type == dnsmessage.TypeSRV {
+				err = b.SRVResource(hdr, *body)
+			}
+		case *dnsmessage.TXTResource:
+			if q.Type == dnsmessage.TypeTXT {
+				err = b.TXTResource(hdr, *body)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.Finish()
+}
+
+func TestDNSCheckerA(t *testing.T) {
+	dns := newStubDNS(t)
+	dns.set("api.test.",
+		&dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}},
+		&dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}},
+	)
+
+	res := check(t, checkers.NewDNSChecker("dns", "api.test.", checkers.RecordA, true,
+		checkers.WithDNSServer(dns.addr),
+		checkers.WithMinRecords(2),
+		checkers.WithExpectRecords("10.0.0.1"),
+	))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	if res.Details["answers"] != "10.0.0.1, 10.0.0.2" || res.Details["count"] != "2" || res.Details["latency"] == "" {
+		t.Fatalf("unexpected details: %v", res.Details)
+	}
+
+	res = check(t, checkers.NewDNSChecker("dns", "api.test.", checkers.RecordA, true,
+		checkers.WithDNSServer(dns.addr), checkers.WithMinRecords(3)))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+
+	res = check(t, checkers.NewDNSChecker("dns", "api.test.", checkers.RecordA, true,
+		checkers.WithDNSServer(dns.addr), checkers.WithExpectRecords("10.0.0.9")))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "missing expected records: 10.0.0.9" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	res = check(t, checkers.NewDNSChecker("dns", "missing.test.", checkers.RecordA, true,
+		checkers.WithDNSServer(dns.addr)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error == "" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestDNSCheckerPinned(t *testing.T) {
+	dns := newStubDNS(t)
+	dns.set("api.test.", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})
+
+	c := checkers.NewDNSChecker("dns", "api.test.", checkers.RecordA, true,
+		checkers.WithDNSServer(dns.addr), checkers.WithPinnedRecords("10.0.0.1"))
+	if res := check(t, c); res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	dns.set("api.test.", &dnsmessage.AResource{A: [4]byte{10, 0, 0, 7}})
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Message != "answers changed: +10.0.0.7 -10.0.0.1" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
+
+func TestDNSCheckerRecordTypes(t *testing.T) {
+	dns := newStubDNS(t)
+	dns.set("v6.test.", &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}})
+	dns.set("www.test.", &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("Edge.CDN.test.")})
+	dns.set("_sip._udp.test.", &dnsmessage.SRVResource{Priority: 10, Weight: 5, Port: 5060, Target: dnsmessage.MustNewName("sip.test.")})
+	dns.set("txt.test.", &dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}})
+
+	tests := []struct {
+		host  string
+		rtype checkers.RecordType
+		want  string
+	}{
+		{"v6.test.", checkers.RecordAAAA, "2001:DB8::1"},
+		{"www.test.", checkers.RecordCNAME, "edge.cdn.test"},
+		{"_sip._udp.test.", checkers.RecordSRV, "10 5 5060 sip.test."},
+		{"txt.test.", checkers.RecordTXT, "v=spf1 -all"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.rtype), func(t *testing.T) {
+			res := check(t, checkers.NewDNSChecker("dns", tt.host, tt.rtype, true,
+				checkers.WithDNSServer(dns.addr), checkers.WithPinnedRecords(tt.want)))
+			if res.Status != pb.Status_STATUS_UP {
+				t.Fatalf("got %s: %s %s (answers %q)", res.Status, res.Message, res.Error, res.Details["answers"])
+			}
+		})
+	}
+}
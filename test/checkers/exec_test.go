@@ -0,0 +1,138 @@
+//go:build unix
+
+package checkers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+func plugin(t *testing.T, name string) string {
+	t.Helper()
+	path, err := filepath.Abs(filepath.Join("testdata", "exec", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExecCheckerPerfdata(t *testing.T) {
+	res := check(t, checkers.NewExecChecker("disk", plugin(t, "check_disk.sh"), nil, true))
+	if res.Status != pb.Status_STATUS_UP || res.Message != "DISK OK - free space: / 3326 MB (56%)" {
+		t.Fatalf("got %s: %q %s", res.Status, res.Message, res.Error)
+	}
+	for key, want := range map[string]string{
+		"exit_code":           "0",
+		"perf./":              "2643MB",
+		"perf./.warn":         "5948",
+		"perf./.crit":         "5958",
+		"perf./.min":          "0",
+		"perf./.max":          "5968",
+		"perf.inode use":      "12%",
+		"perf.inode use.crit": "90",
+		"perf.it's":           "1",
+	} {
+		if res.Details[key] != want {
+			t.Errorf("details[%s] = %q, want %q", key, res.Details[key], want)
+		}
+	}
+	if _, ok := res.Details["perf.inode use.min"]; ok {
+		t.Errorf("empty min reported: %v", res.Details)
+	}
+}
+
+func TestExecCheckerExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	for code, want := range map[int]pb.Status{
+		0: pb.Status_STATUS_UP,
+		1: pb.Status_STATUS_DEGRADED,
+		2: pb.Status_STATUS_DOWN,
+		3: pb.Status_STATUS_UNKNOWN,
+		7: pb.Status_STATUS_UNKNOWN,
+	} {
+		res := check(t, checkers.NewExecChecker("env", plugin(t, "check_env.sh"), []string{strconv.Itoa(code)}, true,
+			checkers.WithExecEnv("LEVEL=high"),
+			checkers.WithExecDir(dir)))
+		if res.Status != want || res.Details["exit_code"] != strconv.Itoa(code) {
+			t.Errorf("exit %d: got %s %v", code, res.Status, res.Details)
+		}
+		if res.Message != "LEVEL high in "+filepath.Base(dir) || res.Details["perf.level"] != "high" {
+			t.Errorf("exit %d: message %q, details %v", code, res.Message, res.Details)
+		}
+	}
+}
+
+func TestExecCheckerMultiline(t *testing.T) {
+	res := check(t, checkers.NewExecChecker("queues", plugin(t, "check_multi.sh"), nil, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "QUEUE CRITICAL - 3 queues stuck" {
+		t.Fatalf("got %s: %q", res.Status, res.Message)
+	}
+	if res.Details["output"] != "orders: 1200 messages\nemails: 900 messages" {
+		t.Errorf("output = %q", res.Details["output"])
+	}
+	if res.Details["perf.stuck.crit"] != "2" || res.Details["perf.orders"] != "1200c" || res.Details["perf.emails"] != "900c" {
+		t.Errorf("details = %v", res.Details)
+	}
+	if res.Error != "broker unreachable" {
+		t.Errorf("error = %q", res.Error)
+	}
+}
+
+func TestExecCheckerTimeoutKillsGroup(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "survived")
+	start := time.Now()
+	res := check(t, checkers.NewExecChecker("hang", plugin(t, "check_hang.sh"), []string{marker}, true,
+		checkers.WithExecTimeout(100*time.Millisecond)))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.HasPrefix(res.Message, "killed after") {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("check took %s", elapsed)
+	}
+
+	// The forked child would create the marker after 0.5s had it survived.
+	time.Sleep(800 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("forked child outlived the timeout")
+	}
+}
+
+func TestExecCheckerParentCanceled(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "survived")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	res, err := checkers.NewExecChecker("hang", plugin(t, "check_hang.sh"), []string{marker}, true).Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "canceled" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
+
+func TestExecCheckerChildHoldsStdout(t *testing.T) {
+	start := time.Now()
+	res := check(t, checkers.NewExecChecker("daemon", plugin(t, "check_daemon.sh"), nil, true))
+	if res.Status != pb.Status_STATUS_UP || res.Details["exit_code"] != "0" || res.Message != "OK - daemon started" {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("check took %s", elapsed)
+	}
+}
+
+func TestExecCheckerStartFailure(t *testing.T) {
+	res := check(t, checkers.NewExecChecker("missing", filepath.Join(t.TempDir(), "nope"), nil, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "start failed" || res.Error == "" {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+}
@@ -0,0 +1,160 @@
+package checkers
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const healthCheck = "grpc.health.v1.Health/Check"
+
+// serveGRPC starts an in-process server exposing grpc.health.v1 with the
+// "api" service SERVING, an unimplemented srvmon.v1 service whose file has
+// imports, and server reflection when reflect is set.
+func serveGRPC(t *testing.T, reflect bool, opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(opts...)
+	hs := health.NewServer()
+	hs.SetServingStatus("api", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(s, hs)
+	pb.RegisterSrvmonServer(s, pb.UnimplementedSrvmonServer{})
+	if reflect {
+		reflection.Register(s)
+	}
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestGRPCCheckerReflection(t *testing.T) {
+	conn := serveGRPC(t, true)
+
+	c := checkers.NewGRPCChecker("health", conn, "/"+healthCheck, true,
+		checkers.WithGRPCRequest(`{"service": "api"}`),
+		checkers.WithGRPCJSONPath("$.status", "SERVING"))
+	for range 2 { // the second check reuses the resolved descriptors
+		res := check(t, c)
+		if res.Status != pb.Status_STATUS_UP || res.Details["code"] != "OK" || res.Details["latency"] == "" {
+			t.Fatalf("got %s: %s %s %v", res.Status, res.Message, res.Error, res.Details)
+		}
+	}
+
+	res := check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true,
+		checkers.WithGRPCRequest(`{"service": "api"}`),
+		checkers.WithGRPCJSONPath("$.status", "NOT_SERVING")))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != `$.status = "SERVING", want "NOT_SERVING"` {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestGRPCCheckerStatusCodes(t *testing.T) {
+	conn := serveGRPC(t, true)
+
+	res := check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true,
+		checkers.WithGRPCRequest(`{"service": "missing"}`)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "unexpected status NotFound" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	res = check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true,
+		checkers.WithGRPCRequest(`{"service": "missing"}`),
+		checkers.WithGRPCExpectCode(codes.OK, codes.NotFound)))
+	if res.Status != pb.Status_STATUS_UP || res.Details["code"] != "NotFound" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestGRPCCheckerReflectsImports(t *testing.T) {
+	conn := serveGRPC(t, true)
+
+	res := check(t, checkers.NewGRPCChecker("srvmon", conn, "srvmon.v1.srvmon/Health", true,
+		checkers.WithGRPCExpectCode(codes.Unimplemented)))
+	if res.Status != pb.Status_STATUS_UP || res.Details["code"] != "Unimplemented" {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+}
+
+func TestGRPCCheckerDescriptorSet(t *testing.T) {
+	conn := serveGRPC(t, false)
+
+	res := check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "resolve method failed" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(grpc_health_v1.File_grpc_health_v1_health_proto),
+	}}
+	res = check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true,
+		checkers.WithGRPCDescriptors(set),
+		checkers.WithGRPCJSONPath("status", "SERVING")))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+}
+
+func TestGRPCCheckerMetadata(t *testing.T) {
+	conn := serveGRPC(t, true, grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if md, _ := metadata.FromIncomingContext(ctx); strings.Join(md.Get("x-token"), "") != "secret" {
+				return nil, status.Error(codes.Unauthenticated, "missing token")
+			}
+			return handler(ctx, req)
+		}))
+
+	res := check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["code"] != "Unauthenticated" {
+		t.Fatalf("got %s: %v", res.Status, res.Details)
+	}
+
+	res = check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true,
+		checkers.WithGRPCMetadata("x-token", "secret")))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+}
+
+func TestGRPCCheckerInvalidMethod(t *testing.T) {
+	conn := serveGRPC(t, true)
+
+	for method, want := range map[string]string{
+		"grpc.health.v1.Health/Nope":  "service grpc.health.v1.Health has no method Nope",
+		"grpc.health.v1.Health/Watch": "grpc.health.v1.Health/Watch is a streaming method",
+		"grpc.health.v1.Health":       `method "grpc.health.v1.Health" is not of the form package.Service/Method`,
+	} {
+		res := check(t, checkers.NewGRPCChecker("health", conn, method, true))
+		if res.Status != pb.Status_STATUS_DOWN || res.Error != want {
+			t.Errorf("%s: got %s: %s", method, res.Status, res.Error)
+		}
+	}
+
+	res := check(t, checkers.NewGRPCChecker("health", conn, healthCheck, true,
+		checkers.WithGRPCRequest(`{"unknown": 1}`)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "invalid request" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
@@ -0,0 +1,87 @@
+package checkers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+func TestHeartbeatCheckerTransitions(t *testing.T) {
+	hb := checkers.NewHeartbeatChecker("outbox", true, checkers.WithBeatIntervals(50*time.Millisecond, 400*time.Millisecond))
+
+	res := check(t, hb)
+	if res.Status != pb.Status_STATUS_UP || res.Message != "waiting for first beat" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	hb.BeatWith(map[string]string{"offset": "41"})
+	hb.BeatWith(map[string]string{"offset": "42", "items": "7"})
+	res = check(t, hb)
+	if res.Status != pb.Status_STATUS_UP || res.Details["beats"] != "2" || res.Details["last_beat"] == "" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+	if res.Details["progress.offset"] != "42" || res.Details["progress.items"] != "7" {
+		t.Errorf("progress = %v", res.Details)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if res = check(t, hb); res.Status != pb.Status_STATUS_DEGRADED {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+	time.Sleep(350 * time.Millisecond)
+	if res = check(t, hb); res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	hb.Beat()
+	if res = check(t, hb); res.Status != pb.Status_STATUS_UP || res.Details["progress.offset"] != "42" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestHeartbeatCheckerNeverBeat(t *testing.T) {
+	hb := checkers.NewHeartbeatChecker("scheduler", true, checkers.WithBeatIntervals(10*time.Millisecond, 20*time.Millisecond))
+	time.Sleep(30 * time.Millisecond)
+
+	res := check(t, hb)
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["last_beat"] != "" || res.Details["since_last_beat"] == "" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestHeartbeatCheckerConcurrent(t *testing.T) {
+	hb := checkers.NewHeartbeatChecker("consumer", true)
+
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				hb.BeatWith(map[string]string{"worker." + strconv.Itoa(w): strconv.Itoa(i)})
+				if i%25 == 0 {
+					_, _ = hb.Check(context.Background())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	res := check(t, hb)
+	if res.Details["beats"] != "800" || res.Details["progress.worker.3"] != "99" {
+		t.Fatalf("details = %v", res.Details)
+	}
+	// Checking does not reset the rate window.
+	for range 2 {
+		res = check(t, hb)
+		if rate, err := strconv.ParseFloat(strings.TrimSuffix(res.Details["beat_rate"], "/s"), 64); err != nil || rate <= 0 {
+			t.Errorf("beat_rate = %q", res.Details["beat_rate"])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package checkers
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+func newAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status":"ok","replication":{"lag":3,"ok":true},"nodes":[{"name":"a"},{"name":"b"}]}`)
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("X-Token"), body)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/status", http.StatusFound)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, strings.Repeat("x", 4096))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPCheckerStatus(t *testing.T) {
+	srv := newAPI(t)
+
+	if res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/status", true)); res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+	if res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/broken", true)); res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+
+	res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/broken", true,
+		checkers.WithExpectStatus(checkers.StatusRange{Min: 200, Max: 299}, checkers.StatusRange{Min: 500, Max: 500}),
+	))
+	if res.Status != pb.Status_STATUS_UP || res.Details["status_code"] != "500" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+}
+
+func TestHTTPCheckerRequest(t *testing.T) {
+	srv := newAPI(t)
+
+	res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/echo", true,
+		checkers.WithMethod(http.MethodPost),
+		checkers.WithHeader("X-Token", "secret"),
+		checkers.WithBody([]byte("hello")),
+		checkers.WithBodyRegex(regexp.MustCompile(`^POST secret hello$`)),
+	))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestHTTPCheckerJSONPath(t *testing.T) {
+	srv := newAPI(t)
+
+	res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/status", true,
+		checkers.WithJSONPath("$.status", "ok"),
+		checkers.WithJSONPath("$.replication.ok", "true"),
+		checkers.WithJSONPath("$.nodes[1].name", "b"),
+	))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewHTTPChecker("api", srv.URL+"/status", true,
+		checkers.WithJSONPath("$.replication.lag", "0"),
+	))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.Contains(res.Error, `"3"`) {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestHTTPCheckerDegraded(t *testing.T) {
+	srv := newAPI(t)
+
+	res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/status", true,
+		checkers.WithJSONPath("$.status", "ok"),
+		checkers.DegradedIf(checkers.WithJSONPath("$.replication.lag", "0")),
+	))
+	if res.Status != pb.Status_STATUS_DEGRADED {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewHTTPChecker("api", srv.URL+"/broken", true,
+		checkers.DegradedIf(checkers.WithJSONPath("$.replication.lag", "0")),
+	))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("DOWN rule not preferred: got %s", res.Status)
+	}
+}
+
+func TestHTTPCheckerRedirects(t *testing.T) {
+	srv := newAPI(t)
+
+	res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/moved", true))
+	if res.Status != pb.Status_STATUS_UP || res.Details["final_url"] != srv.URL+"/status" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+
+	res = check(t, checkers.NewHTTPChecker("api", srv.URL+"/moved", true, checkers.WithRedirects(0)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["status_code"] != "302" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+}
+
+func TestHTTPCheckerMaxBodySize(t *testing.T) {
+	srv := newAPI(t)
+
+	res := check(t, checkers.NewHTTPChecker("api", srv.URL+"/large", true, checkers.WithMaxBodySize(1024)))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+}
+
+func TestHTTPCheckerTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	if res := check(t, checkers.NewHTTPChecker("tls", srv.URL, true)); res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("untrusted certificate accepted: %s", res.Status)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	res := check(t, checkers.NewHTTPChecker("tls", srv.URL, true,
+		checkers.WithRootCAs(pool),
+		checkers.WithServerName("example.com"),
+	))
+	if res.Status != pb.Status_STATUS_UP || res.Details["tls_version"] == "" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	if res := check(t, checkers.NewHTTPChecker("tls", srv.URL, true, checkers.WithInsecureSkipVerify())); res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
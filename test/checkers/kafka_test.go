@@ -0,0 +1,152 @@
+package checkers
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const (
+	kafkaMetadata    = 3
+	kafkaAPIVersions = 18
+)
+
+// loadFrame reads a recorded response body (without size and correlation id)
+// from testdata/kafka.
+func loadFrame(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "kafka", name+".hex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := hex.DecodeString(strings.Join(strings.Fields(string(raw)), ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// fakeBroker replays the recorded response for each request's API key,
+// echoing the request's correlation id. It hangs up on Metadata requests
+// that are not v4 with auto topic creation disabled.
+func fakeBroker(t *testing.T, responses map[int16][]byte) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				for {
+					var size [4]byte
+					if _, err := io.ReadFull(conn, size[:]); err != nil {
+						return
+					}
+					req := make([]byte, binary.BigEndian.Uint32(size[:]))
+					if _, err := io.ReadFull(conn, req); err != nil {
+						return
+					}
+					key, version := int16(binary.BigEndian.Uint16(req)), binary.BigEndian.Uint16(req[2:])
+					if key == kafkaMetadata && (version != 4 || req[len(req)-1] != 0) {
+						return
+					}
+					body, ok := responses[key]
+					if !ok {
+						return
+					}
+					frame := binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))
+					frame = append(frame, req[4:8]...) // correlation id
+					if _, err := conn.Write(append(frame, body...)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestKafkaCheckerHealthy(t *testing.T) {
+	addr := fakeBroker(t, map[int16][]byte{
+		kafkaAPIVersions: loadFrame(t, "api_versions"),
+		kafkaMetadata:    loadFrame(t, "metadata_healthy"),
+	})
+
+	res := check(t, checkers.NewKafkaChecker("kafka", addr, true, checkers.WithKafkaTopics("orders")))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	for key, want := range map[string]string{
+		"cluster_id":                  "srvmon-test",
+		"controller_id":               "2",
+		"brokers":                     "3",
+		"topic.orders.partitions":     "2",
+		"under_replicated_partitions": "1",
+		"offline_partitions":          "0",
+	} {
+		if res.Details[key] != want {
+			t.Errorf("details[%s] = %q, want %q", key, res.Details[key], want)
+		}
+	}
+}
+
+func TestKafkaCheckerOfflinePartitions(t *testing.T) {
+	addr := fakeBroker(t, map[int16][]byte{
+		kafkaAPIVersions: loadFrame(t, "api_versions"),
+		kafkaMetadata:    loadFrame(t, "metadata_offline"),
+	})
+
+	res := check(t, checkers.NewKafkaChecker("kafka", addr, true, checkers.WithKafkaTopics("orders")))
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["offline_partitions"] != "1" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+}
+
+func TestKafkaCheckerFailures(t *testing.T) {
+	addr := fakeBroker(t, map[int16][]byte{
+		kafkaAPIVersions: loadFrame(t, "api_versions"),
+		kafkaMetadata:    loadFrame(t, "metadata_unknown_topic"),
+	})
+	res := check(t, checkers.NewKafkaChecker("kafka", addr, true, checkers.WithKafkaTopics("payments")))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "unknown topics: payments" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	addr = fakeBroker(t, map[int16][]byte{
+		kafkaAPIVersions: loadFrame(t, "api_versions"),
+		kafkaMetadata:    loadFrame(t, "metadata_leader_unavailable"),
+	})
+	res = check(t, checkers.NewKafkaChecker("kafka", addr, true, checkers.WithKafkaTopics("orders")))
+	if res.Status != pb.Status_STATUS_DEGRADED || !strings.Contains(res.Message, "orders (error 5)") {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	addr = fakeBroker(t, map[int16][]byte{
+		kafkaAPIVersions: loadFrame(t, "api_versions_old"),
+	})
+	res = check(t, checkers.NewKafkaChecker("kafka", addr, true))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.Contains(res.Message, "metadata v0 to v0, need v4") {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	addr = fakeBroker(t, map[int16][]byte{kafkaAPIVersions: {0, 0, 0}})
+	res = check(t, checkers.NewKafkaChecker("kafka", addr, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != "truncated response" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
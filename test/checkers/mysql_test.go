@@ -0,0 +1,263 @@
+package checkers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+const mysqlSalt = "abcdefghijklmnopqrst"
+
+// fakeMySQL scripts the server side of a MySQL login.
+type fakeMySQL struct {
+	greeting []byte // sent instead of mysqlHandshake when set
+	plugin   string // mysql_native_password or caching_sha2_password
+	user     string
+	password string
+	fullAuth bool // caching_sha2_password cache miss: RSA exchange
+	key      *rsa.PrivateKey
+
+	quit chan struct{} // receives on COM_QUIT
+}
+
+func (f *fakeMySQL) listen(t *testing.T) string {
+	t.Helper()
+	if f.fullAuth {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.key = key
+	}
+	f.quit = make(chan struct{}, 8)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func (f *fakeMySQL) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	if f.greeting != nil {
+		_ = writePacket(conn, 0, f.greeting)
+		return
+	}
+	_ = writePacket(conn, 0, mysqlHandshake("8.0.36", f.plugin))
+
+	login, seq, err := readPacket(conn)
+	if err != nil || len(login) < 32 {
+		return
+	}
+	user, rest, _ := bytes.Cut(login[32:], []byte{0})
+	auth := rest[1 : 1+rest[0]]
+
+	ok := string(user) == f.user
+	switch {
+	case !ok:
+	case f.password == "":
+		ok = len(auth) == 0
+	case f.plugin == "mysql_native_password":
+		ok = bytes.Equal(auth, nativeScramble(f.password))
+	case !f.fullAuth:
+		ok = bytes.Equal(auth, sha2Scramble(f.password))
+		_ = writePacket(conn, seq+1, []byte{0x01, 0x03})
+		seq++
+	default:
+		ok = f.rsaExchange(conn, &seq)
+	}
+
+	if !ok {
+		denied := append([]byte{0xff}, binary.LittleEndian.AppendUint16(nil, 1045)...)
+		_ = writePacket(conn, seq+1, append(denied, "#28000Access denied for user"...))
+		return
+	}
+	_ = writePacket(conn, seq+1, []byte{0x00, 0, 0, 2, 0, 0, 0})
+
+	if cmd, seq, err := readPacket(conn); err == nil && seq == 0 && bytes.Equal(cmd, []byte{0x01}) {
+		f.quit <- struct{}{}
+	}
+}
+
+// rsaExchange asks for a full login, sends the public key on request and
+// decrypts the password.
+func (f *fakeMySQL) rsaExchange(conn net.Conn, seq *byte) bool {
+	_ = writePacket(conn, *seq+1, []byte{0x01, 0x04})
+	req, s, err := readPacket(conn)
+	if err != nil || !bytes.Equal(req, []byte{0x02}) {
+		return false
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&f.key.PublicKey)
+	_ = writePacket(conn, s+1, append([]byte{0x01}, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...))
+	enc, s, err := readPacket(conn)
+	if err != nil {
+		return false
+	}
+	*seq = s
+	plain, err := rsa.DecryptOAEP(sha1.New(), nil, f.key, enc, nil)
+	if err != nil {
+		return false
+	}
+	for i := range plain {
+		plain[i] ^= mysqlSalt[i%len(mysqlSalt)]
+	}
+	return string(plain) == f.password+"\x00"
+}
+
+func readPacket(r io.Reader) ([]byte, byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, 0, err
+	}
+	p := make([]byte, int(hdr[0])|int(hdr[1])<<8|int(hdr[2])<<16)
+	_, err := io.ReadFull(r, p)
+	return p, hdr[3], err
+}
+
+func writePacket(w io.Writer, seq byte, p []byte) error {
+	_, err := w.Write(append([]byte{byte(len(p)), byte(len(p) >> 8), byte(len(p) >> 16), seq}, p...))
+	return err
+}
+
+func nativeScramble(password string) []byte {
+	h1 := sha1.Sum([]byte(password))
+	h2 := sha1.Sum(h1[:])
+	h3 := sha1.Sum(append([]byte(mysqlSalt), h2[:]...))
+	for i := range h1 {
+		h1[i] ^= h3[i]
+	}
+	return h1[:]
+}
+
+func sha2Scramble(password string) []byte {
+	d1 := sha256.Sum256([]byte(password))
+	d2 := sha256.Sum256(d1[:])
+	d3 := sha256.Sum256(append(d2[:], mysqlSalt...))
+	for i := range d1 {
+		d1[i] ^= d3[i]
+	}
+	return d1[:]
+}
+
+func mysqlHandshake(version, plugin string) []byte {
+	p := append([]byte{10}, version...)
+	p = append(p, 0)
+	p = binary.LittleEndian.AppendUint32(p, 42)     // connection id
+	p = append(p, mysqlSalt[:8]...)                 // auth data part 1
+	p = append(p, 0)                                // filler
+	p = binary.LittleEndian.AppendUint16(p, 0xffff) // capabilities low, including SSL
+	p = append(p, 0x21)                             // charset
+	p = binary.LittleEndian.AppendUint16(p, 0x0002) // status flags
+	p = binary.LittleEndian.AppendUint16(p, 0x00ff) // capabilities high
+	p = append(p, 21)                               // auth data length
+	p = append(p, make([]byte, 10)...)              // reserved
+	p = append(p, mysqlSalt[8:]...)                 // auth data part 2
+	p = append(p, 0)
+	p = append(p, plugin...)
+	return append(p, 0)
+}
+
+func TestMySQLChecker(t *testing.T) {
+	addr := (&fakeMySQL{plugin: "caching_sha2_password", user: "monitor", password: "secret"}).listen(t)
+
+	res := check(t, checkers.NewMySQLChecker("mysql", addr, true))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+	for key, want := range map[string]string{
+		"server_version": "8.0.36",
+		"connection_id":  "42",
+		"auth_plugin":    "caching_sha2_password",
+		"tls_supported":  "true",
+		"login":          "denied",
+	} {
+		if res.Details[key] != want {
+			t.Errorf("details[%s] = %q, want %q", key, res.Details[key], want)
+		}
+	}
+}
+
+func TestMySQLCheckerLogin(t *testing.T) {
+	for _, f := range []*fakeMySQL{
+		{plugin: "mysql_native_password", user: "monitor", password: "secret"},
+		{plugin: "caching_sha2_password", user: "monitor", password: "secret"},
+		{plugin: "caching_sha2_password", user: "monitor", password: "secret", fullAuth: true},
+		{plugin: "caching_sha2_password", user: "monitor"},
+	} {
+		name := f.plugin
+		if f.fullAuth {
+			name += " full"
+		}
+		if f.password == "" {
+			name += " no password"
+		}
+		t.Run(name, func(t *testing.T) {
+			addr := f.listen(t)
+
+			opts := []checkers.MySQLOption{checkers.WithMySQLUser(f.user, f.password)}
+			if f.fullAuth {
+				opts = append(opts, checkers.WithMySQLPublicKeyRetrieval())
+			}
+			res := check(t, checkers.NewMySQLChecker("mysql", addr, true, opts...))
+			if res.Status != pb.Status_STATUS_UP || res.Details["login"] != "ok" {
+				t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+			}
+			<-f.quit
+
+			opts[0] = checkers.WithMySQLUser(f.user, "wrong")
+			res = check(t, checkers.NewMySQLChecker("mysql", addr, true, opts...))
+			if res.Status != pb.Status_STATUS_DOWN || res.Message != "login failed" || res.Error != "error 1045: Access denied for user" {
+				t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+			}
+		})
+	}
+}
+
+func TestMySQLCheckerKeyRetrievalOptIn(t *testing.T) {
+	addr := (&fakeMySQL{plugin: "caching_sha2_password", user: "monitor", password: "secret", fullAuth: true}).listen(t)
+
+	res := check(t, checkers.NewMySQLChecker("mysql", addr, true, checkers.WithMySQLUser("monitor", "secret")))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.Contains(res.Error, "WithMySQLPublicKeyRetrieval") {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+}
+
+func TestMySQLCheckerRefused(t *testing.T) {
+	errPacket := append([]byte{0xff}, binary.LittleEndian.AppendUint16(nil, 1040)...)
+	errPacket = append(errPacket, "#08004Too many connections"...)
+	addr := (&fakeMySQL{greeting: errPacket}).listen(t)
+
+	res := check(t, checkers.NewMySQLChecker("mysql", addr, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != "error 1040: Too many connections" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewMySQLChecker("mysql", closedTCP(t), true))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+}
@@ -0,0 +1,274 @@
+package checkers
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// fakePostgres scripts the server side of a PostgreSQL session start.
+type fakePostgres struct {
+	auth     string // trust, password, md5 or scram
+	user     string
+	password string
+	tls      *tls.Config
+	params   map[string]string
+	fatal    string // SQLSTATE sent instead of authenticating, e.g. 57P03
+	iter     int    // SCRAM iteration count; 0 means 4096
+}
+
+func (f *fakePostgres) listen(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func (f *fakePostgres) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	startup, err := readStartup(conn)
+	if err != nil {
+		return
+	}
+	if binary.BigEndian.Uint32(startup) == 80877103 { // SSLRequest
+		if f.tls == nil {
+			_, _ = conn.Write([]byte{'N'})
+			return
+		}
+		_, _ = conn.Write([]byte{'S'})
+		tc := tls.Server(conn, f.tls)
+		conn = tc
+		if startup, err = readStartup(conn); err != nil {
+			return
+		}
+	}
+
+	params := make(map[string]string)
+	fields := strings.Split(string(startup[4:]), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		params[fields[i]] = fields[i+1]
+	}
+
+	r := bufio.NewReader(conn)
+	if f.fatal != "" {
+		writeMsg(conn, 'E', []byte("SFATAL\x00C"+f.fatal+"\x00Mthe database system is starting up\x00\x00"))
+		return
+	}
+	if !f.authenticate(conn, r, params["user"]) {
+		writeMsg(conn, 'E', []byte("SFATAL\x00C28P01\x00Mpassword authentication failed\x00\x00"))
+		return
+	}
+
+	writeMsg(conn, 'R', authCode(0))
+	for k, v := range f.params {
+		writeMsg(conn, 'S', []byte(k+"\x00"+v+"\x00"))
+	}
+	writeMsg(conn, 'K', make([]byte, 8))
+	writeMsg(conn, 'Z', []byte{'I'})
+	_, _, _ = readMsg(r) // Terminate
+}
+
+func (f *fakePostgres) authenticate(conn net.Conn, r *bufio.Reader, user string) bool {
+	switch f.auth {
+	case "password":
+		writeMsg(conn, 'R', authCode(3))
+		_, body, err := readMsg(r)
+		return err == nil && string(body) == f.password+"\x00"
+
+	case "md5":
+		salt := []byte{1, 2, 3, 4}
+		writeMsg(conn, 'R', append(authCode(5), salt...))
+		_, body, err := readMsg(r)
+		inner := md5.Sum([]byte(f.password + user))
+		outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+		return err == nil && string(body) == "md5"+hex.EncodeToString(outer[:])+"\x00"
+
+	case "scram":
+		writeMsg(conn, 'R', append(authCode(10), "SCRAM-SHA-256\x00\x00"...))
+		_, body, err := readMsg(r)
+		if err != nil {
+			return false
+		}
+		// mechanism\0, int32 length, client-first-message
+		_, rest, _ := strings.Cut(string(body), "\x00")
+		clientFirstBare := strings.TrimPrefix(rest[4:], "n,,")
+		clientNonce := strings.TrimPrefix(clientFirstBare, "n=,r=")
+
+		salt := []byte("fakesalt")
+		iter := f.iter
+		if iter == 0 {
+			iter = 4096
+		}
+		serverFirst := "r=" + clientNonce + "server,s=" + base64.StdEncoding.EncodeToString(salt) + ",i=" + strconv.Itoa(iter)
+		writeMsg(conn, 'R', append(authCode(11), serverFirst...))
+
+		_, body, err = readMsg(r)
+		if err != nil {
+			return false
+		}
+		final := string(body)
+		withoutProof, proof64, _ := strings.Cut(final, ",p=")
+		authMessage := clientFirstBare + "," + serverFirst + "," + withoutProof
+
+		salted, _ := pbkdf2.Key(sha256.New, f.password, salt, iter, sha256.Size)
+		clientKey := mac(salted, "Client Key")
+		storedKey := sha256.Sum256(clientKey)
+		signature := mac(storedKey[:], authMessage)
+		proof, _ := base64.StdEncoding.DecodeString(proof64)
+		for i := range proof {
+			proof[i] ^= signature[i]
+		}
+		if got := sha256.Sum256(proof); !hmac.Equal(got[:], storedKey[:]) {
+			return false
+		}
+
+		serverSig := mac(mac(salted, "Server Key"), authMessage)
+		writeMsg(conn, 'R', append(authCode(12), "v="+base64.StdEncoding.EncodeToString(serverSig)...))
+		return true
+
+	default:
+		return true
+	}
+}
+
+func readStartup(conn net.Conn) ([]byte, error) {
+	var n [4]byte
+	if _, err := io.ReadFull(conn, n[:]); err != nil {
+		return nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(n[:])-4)
+	_, err := io.ReadFull(conn, body)
+	return body, err
+}
+
+func readMsg(r *bufio.Reader) (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(hdr[1:])-4)
+	_, err := io.ReadFull(r, body)
+	return hdr[0], body, err
+}
+
+func writeMsg(w io.Writer, typ byte, body []byte) {
+	msg := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	_, _ = w.Write(append(msg, body...))
+}
+
+func authCode(code uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, code)
+}
+
+func mac(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+func TestPostgresCheckerAuth(t *testing.T) {
+	for _, method := range []string{"trust", "password", "md5", "scram"} {
+		t.Run(method, func(t *testing.T) {
+			addr := (&fakePostgres{
+				auth:     method,
+				password: "s3cret",
+				params:   map[string]string{"server_version": "16.2"},
+			}).listen(t)
+
+			res := check(t, checkers.NewPostgresChecker("pg", addr, true,
+				checkers.WithPostgresUser("app", "s3cret"), checkers.WithPostgresDatabase("orders")))
+			if res.Status != pb.Status_STATUS_UP || res.Details["server_version"] != "16.2" {
+				t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+			}
+
+			if method == "trust" {
+				return
+			}
+			res = check(t, checkers.NewPostgresChecker("pg", addr, true,
+				checkers.WithPostgresUser("app", "wrong")))
+			if res.Status != pb.Status_STATUS_DOWN || !strings.Contains(res.Error, "28P01") {
+				t.Fatalf("got %s: %s", res.Status, res.Error)
+			}
+		})
+	}
+}
+
+func TestPostgresCheckerScramIterations(t *testing.T) {
+	addr := (&fakePostgres{auth: "scram", password: "s3cret", iter: 1 << 30}).listen(t)
+
+	start := time.Now()
+	res := check(t, checkers.NewPostgresChecker("pg", addr, true,
+		checkers.WithPostgresUser("app", "s3cret")))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.Contains(res.Error, "iteration count") {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("key derived anyway: took %s", elapsed)
+	}
+}
+
+func TestPostgresCheckerRecovery(t *testing.T) {
+	addr := (&fakePostgres{params: map[string]string{
+		"server_version":                "16.2",
+		"in_hot_standby":                "on",
+		"default_transaction_read_only": "on",
+	}}).listen(t)
+
+	res := check(t, checkers.NewPostgresChecker("pg", addr, true))
+	if res.Status != pb.Status_STATUS_UP || res.Details["in_recovery"] != "true" || res.Details["read_only"] != "true" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+
+	addr = (&fakePostgres{fatal: "57P03"}).listen(t)
+	res = check(t, checkers.NewPostgresChecker("pg", addr, true))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.Contains(res.Error, "starting up") {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestPostgresCheckerTLS(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := &tls.Config{Certificates: []tls.Certificate{ca.issue(t, time.Hour)}}
+	addr := (&fakePostgres{tls: cfg, params: map[string]string{"server_version": "16.2"}}).listen(t)
+
+	res := check(t, checkers.NewPostgresChecker("pg", addr, true,
+		checkers.WithPostgresTLS(&tls.Config{RootCAs: ca.pool})))
+	if res.Status != pb.Status_STATUS_UP || res.Details["tls_version"] == "" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+
+	plain := (&fakePostgres{}).listen(t)
+	res = check(t, checkers.NewPostgresChecker("pg", plain, true,
+		checkers.WithPostgresTLS(&tls.Config{RootCAs: ca.pool})))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+}
@@ -0,0 +1,142 @@
+package checkers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// copyPressure copies a fixture root from testdata/pressure into a temporary
+// directory the test can rewrite between checks.
+func copyPressure(t *testing.T, fixture string) string {
+	t.Helper()
+	root := t.TempDir()
+	src := filepath.Join("testdata", "pressure", fixture)
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func writeFixture(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPressureCheckerFirstSample(t *testing.T) {
+	res := check(t, checkers.NewPressureChecker("pressure", false,
+		checkers.WithPressureRoot(filepath.Join("testdata", "pressure", "idle"))))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	for key, want := range map[string]string{
+		"cpu.some_avg10": "0.5%",
+		"io.full_avg10":  "0.6%",
+		"nr_throttled":   "10",
+		"throttled_usec": "200000",
+	} {
+		if res.Details[key] != want {
+			t.Errorf("details[%s] = %q, want %q", key, res.Details[key], want)
+		}
+	}
+
+	res = check(t, checkers.NewPressureChecker("pressure", false,
+		checkers.WithPressureRoot(filepath.Join("testdata", "pressure", "busy"))))
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Message != "memory pressure 35.0% reached 20.0%" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
+
+func TestPressureCheckerOwnCgroup(t *testing.T) {
+	// The root cgroup has no quota; the service's cgroup named in
+	// /proc/self/cgroup is the one being throttled.
+	res := check(t, checkers.NewPressureChecker("pressure", false,
+		checkers.WithPressureRoot(filepath.Join("testdata", "pressure", "nested"))))
+	if res.Details["nr_throttled"] != "120" || res.Details["throttled_usec"] != "900000" {
+		t.Fatalf("details = %v", res.Details)
+	}
+}
+
+func TestPressureCheckerRates(t *testing.T) {
+	root := copyPressure(t, "idle")
+	c := checkers.NewPressureChecker("pressure", false,
+		checkers.WithPressureRoot(root),
+		checkers.WithMaxPressure(20, 20, 20),
+		checkers.WithPressureWindow(50*time.Millisecond))
+
+	if res := check(t, c); res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	// 80ms of cpu stall and half of 100 periods throttled within ~100ms,
+	// while avg10 still looks calm.
+	time.Sleep(100 * time.Millisecond)
+	writeFixture(t, filepath.Join(root, "proc", "pressure", "cpu"),
+		"some avg10=0.50 avg60=0.40 avg300=0.30 total=1080000\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n")
+	writeFixture(t, filepath.Join(root, "sys", "fs", "cgroup", "cpu.stat"),
+		"usage_usec 5100000\nnr_periods 1100\nnr_throttled 60\nthrottled_usec 250000\n")
+
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_DEGRADED {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+	if !strings.Contains(res.Message, "cpu pressure") || !strings.Contains(res.Message, "throttled periods 0.500 reached 0.250") {
+		t.Errorf("message = %q", res.Message)
+	}
+	if res.Details["throttled_ratio"] != "0.500" || res.Details["memory.some_rate"] != "0.0%" || res.Details["throttled_per_second"] == "" {
+		t.Errorf("details = %v", res.Details)
+	}
+}
+
+func TestPressureCheckerWindow(t *testing.T) {
+	root := copyPressure(t, "idle")
+	c := checkers.NewPressureChecker("pressure", false, checkers.WithPressureRoot(root))
+	check(t, c)
+
+	// A check moments later must not turn 80ms of stall into a rate over a
+	// window of a few milliseconds.
+	writeFixture(t, filepath.Join(root, "proc", "pressure", "cpu"),
+		"some avg10=0.50 avg60=0.40 avg300=0.30 total=1080000\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n")
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_UP || res.Details["cpu.some_rate"] != "" || res.Details["throttled_ratio"] != "" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestPressureCheckerMissingSources(t *testing.T) {
+	root := copyPressure(t, "idle")
+	if err := os.Remove(filepath.Join(root, "sys", "fs", "cgroup", "cpu.stat")); err != nil {
+		t.Fatal(err)
+	}
+	res := check(t, checkers.NewPressureChecker("pressure", false, checkers.WithPressureRoot(root)))
+	if res.Status != pb.Status_STATUS_UP || res.Details["nr_throttled"] != "" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+
+	res = check(t, checkers.NewPressureChecker("pressure", false, checkers.WithPressureRoot(t.TempDir())))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "no pressure data" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
@@ -0,0 +1,225 @@
+package checkers
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// fakeRedis is a RESP server implementing AUTH, SELECT, PING and INFO.
+type fakeRedis struct {
+	username, password string
+	info               string
+}
+
+func (f *fakeRedis) serve(t *testing.T, lis net.Listener) string {
+	t.Helper()
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func (f *fakeRedis) listen(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.serve(t, lis)
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	authed := f.username == "" && f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			user, pass := "default", args[len(args)-1]
+			if len(args) == 3 {
+				user = args[1]
+			}
+			if authed = pass == f.password && (f.username == "" || user == f.username); authed {
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid username-password pair\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "SELECT":
+			if n, _ := strconv.Atoi(args[1]); n < 16 {
+				reply = "+OK\r\n"
+			} else {
+				reply = "-ERR DB index is out of range\r\n"
+			}
+		case cmd == "PING":
+			reply = "+PONG\r\n"
+		case cmd == "INFO":
+			reply = fmt.Sprintf("$%d\r\n%s\r\n", len(f.info), f.info)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisCheckerAuth(t *testing.T) {
+	addr := (&fakeRedis{username: "app", password: "secret"}).listen(t)
+
+	res := check(t, checkers.NewRedisChecker("redis", addr, true,
+		checkers.WithRedisAuth("app", "secret"), checkers.WithRedisDB(2)))
+	if res.Status != pb.Status_STATUS_UP || res.Details["latency"] == "" {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+
+	res = check(t, checkers.NewRedisChecker("redis", addr, true, checkers.WithRedisAuth("app", "wrong")))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "auth failed" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	res = check(t, checkers.NewRedisChecker("redis", addr, true))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.HasPrefix(res.Error, "NOAUTH") {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewRedisChecker("redis", addr, true,
+		checkers.WithRedisAuth("app", "secret"), checkers.WithRedisDB(99)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "select failed" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
+
+func TestRedisCheckerEmptyPassword(t *testing.T) {
+	addr := (&fakeRedis{username: "probe"}).listen(t)
+
+	res := check(t, checkers.NewRedisChecker("redis", addr, true, checkers.WithRedisAuth("probe", "")))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+
+	res = check(t, checkers.NewRedisChecker("redis", addr, true))
+	if res.Status != pb.Status_STATUS_DOWN || !strings.HasPrefix(res.Error, "NOAUTH") {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestRedisCheckerReplication(t *testing.T) {
+	master := (&fakeRedis{info: "# Replication\r\nrole:master\r\nconnected_slaves:2\r\n" +
+		"slave0:ip=10.0.0.2,port=6379,state=online,offset=900,lag=0\r\n" +
+		"slave1:ip=10.0.0.3,port=6379,state=online,offset=1000,lag=1\r\n" +
+		"master_repl_offset:1000\r\n"}).listen(t)
+
+	res := check(t, checkers.NewRedisChecker("redis", master, true, checkers.WithReplicationInfo()))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+	for key, want := range map[string]string{
+		"role":               "master",
+		"connected_replicas": "2",
+		"lag.10.0.0.2:6379":  "100",
+		"lag.10.0.0.3:6379":  "0",
+	} {
+		if res.Details[key] != want {
+			t.Errorf("details[%s] = %q, want %q", key, res.Details[key], want)
+		}
+	}
+
+	replica := (&fakeRedis{info: "# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_port:6379\r\n" +
+		"master_link_status:down\r\nmaster_last_io_seconds_ago:-1\r\n"}).listen(t)
+
+	res = check(t, checkers.NewRedisChecker("redis", replica, true, checkers.WithReplicationInfo()))
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["master"] != "10.0.0.1:6379" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+}
+
+func TestRedisCheckerTLS(t *testing.T) {
+	ca := newTestCA(t)
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{ca.issue(t, time.Hour)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := (&fakeRedis{}).serve(t, lis)
+
+	res := check(t, checkers.NewRedisChecker("redis", addr, true,
+		checkers.WithRedisTLS(&tls.Config{RootCAs: ca.pool, ServerName: "localhost"})))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewRedisChecker("redis", closedTCP(t), true))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s, want DOWN", res.Status)
+	}
+}
+
+func TestRedisCheckerHostileReply(t *testing.T) {
+	for _, reply := range []string{"$2147483647\r\n", "$-5\r\n", "*2147483647\r\n", "*-7\r\n"} {
+		addr := serveLines(t, func(conn net.Conn, r *bufio.Reader) {
+			if _, err := readCommand(r); err == nil {
+				_, _ = io.WriteString(conn, reply)
+			}
+		})
+
+		res := check(t, checkers.NewRedisChecker("redis", addr, true))
+		if res.Status != pb.Status_STATUS_DOWN || !strings.HasPrefix(res.Error, "invalid ") {
+			t.Errorf("%q: got %s: %s", reply, res.Status, res.Error)
+		}
+	}
+
+	// A deeply nested array must fail before it recurses all the way down.
+	addr := serveLines(t, func(conn net.Conn, r *bufio.Reader) {
+		if _, err := readCommand(r); err == nil {
+			_, _ = io.WriteString(conn, strings.Repeat("*1\r\n", 100000))
+		}
+	})
+	res := check(t, checkers.NewRedisChecker("redis", addr, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != "array reply nested too deeply" {
+		t.Errorf("nested: got %s: %s", res.Status, res.Error)
+	}
+}
@@ -0,0 +1,97 @@
+package checkers
+
+import (
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// cgroupFixture points the checker at a fixture instead of the test
+// process's own cgroup, whose usage the test cannot control.
+func cgroupFixture(name string) checkers.RuntimeOption {
+	return checkers.WithCgroupDir(filepath.Join("testdata", "cgroup", name))
+}
+
+func TestRuntimeCheckerGoroutines(t *testing.T) {
+	res := check(t, checkers.NewRuntimeChecker("runtime", true, cgroupFixture("v2-unlimited")))
+	if res.Status != pb.Status_STATUS_UP || res.Details["goroutines"] == "" || res.Details["heap_inuse"] == "" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+
+	res = check(t, checkers.NewRuntimeChecker("runtime", true, cgroupFixture("v2-unlimited"),
+		checkers.WithGoroutineLimits(1, 0)))
+	if res.Status != pb.Status_STATUS_DEGRADED || !strings.HasPrefix(res.Message, "goroutines ") {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	res = check(t, checkers.NewRuntimeChecker("runtime", true, cgroupFixture("v2-unlimited"),
+		checkers.WithGoroutineLimits(1, 2)))
+	if res.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
+
+func TestRuntimeCheckerHeap(t *testing.T) {
+	prev := debug.SetMemoryLimit(1 << 40)
+	t.Cleanup(func() { debug.SetMemoryLimit(prev) })
+
+	res := check(t, checkers.NewRuntimeChecker("runtime", true, cgroupFixture("v2-unlimited"),
+		checkers.WithHeapLimits(1e-9, 0)))
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["gomemlimit"] != "1099511627776" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestRuntimeCheckerGCPause(t *testing.T) {
+	runtime.GC()
+	runtime.GC()
+
+	c := checkers.NewRuntimeChecker("runtime", true, cgroupFixture("v2-unlimited"),
+		checkers.WithGCPauseLimits(time.Nanosecond, time.Hour))
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["gc_pause_p99"] == "" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestRuntimeCheckerLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("file descriptors and cgroups are read on linux only")
+	}
+
+	res := check(t, checkers.NewRuntimeChecker("runtime", true, cgroupFixture("v2-unlimited"),
+		checkers.WithFDLimits(1e-9, 0)))
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["open_fds"] == "" || res.Details["fd_limit"] == "" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+
+	for _, tc := range []struct {
+		fixture string
+		want    pb.Status
+		ratio   string
+		usage   string
+	}{
+		{"v2", pb.Status_STATUS_DEGRADED, "0.879", "943718400"},
+		{"v2-unlimited", pb.Status_STATUS_UP, "", "104857600"},
+		// 95% of the limit in use, but half of it is inactive page cache.
+		{"v2-cache", pb.Status_STATUS_UP, "0.450", "483183820"},
+		{"v1", pb.Status_STATUS_UP, "", "201326592"},
+	} {
+		res := check(t, checkers.NewRuntimeChecker("runtime", true, cgroupFixture(tc.fixture)))
+		if res.Status != tc.want || res.Details["cgroup_memory_ratio"] != tc.ratio || res.Details["cgroup_memory_usage"] != tc.usage {
+			t.Errorf("%s: got %s: %s %v", tc.fixture, res.Status, res.Message, res.Details)
+		}
+	}
+
+	res = check(t, checkers.NewRuntimeChecker("runtime", true, cgroupFixture("v2"),
+		checkers.WithCgroupMemoryLimits(0.5, 0.8)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "cgroup memory 0.879 reached 0.800" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
@@ -0,0 +1,302 @@
+package checkers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+var testS3Creds = checkers.S3Credentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+// fakeS3 stands in for a single-bucket S3 endpoint. It verifies every
+// request's SigV4 signature against its own secret before serving it.
+type fakeS3 struct {
+	bucket string
+	secret string
+	region string
+	// readOnly rejects writes like a bucket policy denying s3:PutObject.
+	readOnly bool
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	ops     []string
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	s := &fakeS3{
+		bucket:  bucket,
+		secret:  testS3Creds.SecretAccessKey,
+		region:  "us-east-1",
+		objects: make(map[string][]byte),
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := s.verify(r, body); err != nil {
+		s3Error(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	var bucket, key string
+	if host, _, _ := net.SplitHostPort(r.Host); strings.HasPrefix(host, s.bucket+".") {
+		bucket, key = s.bucket, strings.TrimPrefix(r.URL.Path, "/")
+	} else {
+		bucket, key, _ = strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	}
+	if bucket != s.bucket {
+		s3Error(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ops = append(s.ops, r.Method+" "+key)
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+	case r.Method == http.MethodPut && s.readOnly:
+		s3Error(w, http.StatusForbidden, "AccessDenied", "Access Denied")
+	case r.Method == http.MethodPut:
+		s.objects[key] = body
+	case r.Method == http.MethodGet:
+		obj, ok := s.objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		_, _ = w.Write(obj)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verify recomputes the signature from the request as received.
+func (s *fakeS3) verify(r *http.Request, body []byte) error {
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return fmt.Errorf("unsupported authorization %q", r.Header.Get("Authorization"))
+	}
+	fields := make(map[string]string)
+	for _, f := range strings.Split(auth, ", ") {
+		k, v, _ := strings.Cut(f, "=")
+		fields[k] = v
+	}
+	akid, scope, _ := strings.Cut(fields["Credential"], "/")
+	if akid != testS3Creds.AccessKeyID {
+		return fmt.Errorf("unknown access key %q", akid)
+	}
+
+	sum := sha256.Sum256(body)
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("payload hash %q does not match body", got)
+	}
+
+	var headers strings.Builder
+	for _, h := range strings.Split(fields["SignedHeaders"], ";") {
+		v := r.Header.Get(h)
+		if h == "host" {
+			v = r.Host
+		}
+		headers.WriteString(h + ":" + v + "\n")
+	}
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, url.QueryEscape(k)+"="+url.QueryEscape(query.Get(k)))
+	}
+	sort.Strings(keys)
+
+	canonical := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		strings.Join(keys, "&"),
+		headers.String(),
+		fields["SignedHeaders"],
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	crHash := sha256.Sum256([]byte(canonical))
+	amzDate := r.Header.Get("X-Amz-Date")
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	date, region := amzDate[:8], strings.Split(scope, "/")[1]
+	if region != s.region {
+		return fmt.Errorf("region %q, want %q", region, s.region)
+	}
+	key := mac([]byte("AWS4"+s.secret), date)
+	key = mac(key, region)
+	key = mac(key, "s3")
+	key = mac(key, "aws4_request")
+	if want := hex.EncodeToString(mac(key, toSign)); fields["Signature"] != want {
+		return fmt.Errorf("signature %s, want %s", fields["Signature"], want)
+	}
+	return nil
+}
+
+func (s *fakeS3) operations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ops...)
+}
+
+func s3Error(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Error><Code>%s</Code><Message>%s</Message></Error>", code, msg)
+}
+
+func TestS3CheckerHeadBucket(t *testing.T) {
+	s3, srv := newFakeS3(t, "assets")
+
+	res := check(t, checkers.NewS3Checker("s3", srv.URL, "assets", true,
+		checkers.WithS3PathStyle(), checkers.WithS3Credentials(testS3Creds)))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	if res.Details["latency.head_bucket"] == "" {
+		t.Errorf("missing head_bucket latency: %v", res.Details)
+	}
+	if ops := s3.operations(); len(ops) != 1 || ops[0] != "HEAD " {
+		t.Errorf("operations = %q", ops)
+	}
+}
+
+func TestS3CheckerCanary(t *testing.T) {
+	s3, srv := newFakeS3(t, "assets")
+
+	res := check(t, checkers.NewS3Checker("s3", srv.URL, "assets", true,
+		checkers.WithS3PathStyle(),
+		checkers.WithS3Credentials(testS3Creds),
+		checkers.WithS3Canary("health/canary object")))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	for _, op := range []string{"head_bucket", "put", "get", "delete"} {
+		if res.Details["latency."+op] == "" {
+			t.Errorf("missing latency.%s: %v", op, res.Details)
+		}
+	}
+	ops := s3.operations()
+	key := strings.TrimPrefix(ops[len(ops)-1], "DELETE ")
+	want := []string{"HEAD ", "PUT " + key, "GET " + key, "DELETE " + key}
+	if strings.Join(ops, "|") != strings.Join(want, "|") || !strings.HasPrefix(key, "health/canary object-") {
+		t.Errorf("operations = %q", ops)
+	}
+	s3.mu.Lock()
+	defer s3.mu.Unlock()
+	if len(s3.objects) != 0 {
+		t.Errorf("canary left behind: %v", s3.objects)
+	}
+}
+
+func TestS3CheckerCanaryConcurrent(t *testing.T) {
+	s3, srv := newFakeS3(t, "assets")
+	c := checkers.NewS3Checker("s3", srv.URL, "assets", true,
+		checkers.WithS3PathStyle(), checkers.WithS3Credentials(testS3Creds), checkers.WithS3Canary(""))
+
+	// Replicas sharing the bucket probe at the same time.
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res := check(t, c); res.Status != pb.Status_STATUS_UP {
+				t.Errorf("got %s: %s %s", res.Status, res.Message, res.Error)
+			}
+		}()
+	}
+	wg.Wait()
+
+	keys := make(map[string]bool)
+	for _, op := range s3.operations() {
+		if key, ok := strings.CutPrefix(op, "PUT "); ok {
+			keys[key] = true
+		}
+	}
+	if len(keys) != 8 {
+		t.Errorf("%d distinct canary keys, want 8", len(keys))
+	}
+}
+
+func TestS3CheckerVirtualHost(t *testing.T) {
+	s3, srv := newFakeS3(t, "assets")
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	// Resolve every host, including assets.s3.test, to the stand-in.
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}}
+	res := check(t, checkers.NewS3Checker("s3", "http://s3.test:"+port, "assets", true,
+		checkers.WithS3HTTPClient(client),
+		checkers.WithS3Credentials(testS3Creds),
+		checkers.WithS3Canary("")))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+	if ops := s3.operations(); len(ops) != 4 || !strings.HasPrefix(ops[1], "PUT .srvmon-canary-") {
+		t.Errorf("operations = %q", ops)
+	}
+}
+
+func TestS3CheckerFailures(t *testing.T) {
+	s3, srv := newFakeS3(t, "assets")
+
+	res := check(t, checkers.NewS3Checker("s3", srv.URL, "missing", true,
+		checkers.WithS3PathStyle(), checkers.WithS3Credentials(testS3Creds)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != "404 Not Found" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	wrong := testS3Creds
+	wrong.SecretAccessKey = "not-the-secret"
+	res = check(t, checkers.NewS3Checker("s3", srv.URL, "assets", true,
+		checkers.WithS3PathStyle(), checkers.WithS3Credentials(wrong)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != "403 Forbidden" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewS3Checker("s3", srv.URL, "assets", true,
+		checkers.WithS3PathStyle(), checkers.WithS3Credentials(testS3Creds), checkers.WithS3Region("eu-west-1")))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "head bucket failed" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	// HEAD responses carry no body; the S3 error document shows up on writes.
+	s3.readOnly = true
+	res = check(t, checkers.NewS3Checker("s3", srv.URL, "assets", true,
+		checkers.WithS3PathStyle(), checkers.WithS3Credentials(testS3Creds), checkers.WithS3Canary("")))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "put canary failed" ||
+		res.Error != "403 Forbidden: AccessDenied: Access Denied" {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+
+	res = check(t, checkers.NewS3Checker("s3", "http://[::1", "assets", true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "invalid endpoint" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
@@ -0,0 +1,166 @@
+package checkers
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// serveLines runs handle for every accepted connection.
+func serveLines(t *testing.T, handle func(conn net.Conn, r *bufio.Reader)) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				handle(conn, bufio.NewReader(conn))
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+// fakeSMTP implements just enough of SMTP for SMTPScript, including STARTTLS.
+func fakeSMTP(cfg *tls.Config) func(net.Conn, *bufio.Reader) {
+	return func(conn net.Conn, r *bufio.Reader) {
+		var w io.Writer = conn
+		_, _ = io.WriteString(w, "220-mail.test ESMTP\r\n220 ready\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch strings.TrimSpace(line) {
+			case "EHLO srvmon":
+				_, _ = io.WriteString(w, "250-mail.test\r\n250-STARTTLS\r\n250 8BITMIME\r\n")
+			case "STARTTLS":
+				_, _ = io.WriteString(w, "220 go ahead\r\n")
+				tc := tls.Server(conn, cfg)
+				r, w = bufio.NewReader(tc), tc
+			case "QUIT":
+				_, _ = io.WriteString(w, "221 bye\r\n")
+				return
+			default:
+				_, _ = io.WriteString(w, "500 unknown\r\n")
+			}
+		}
+	}
+}
+
+func TestScriptCheckerSMTP(t *testing.T) {
+	ca := newTestCA(t)
+	addr := serveLines(t, fakeSMTP(&tls.Config{Certificates: []tls.Certificate{ca.issue(t, time.Hour)}}))
+
+	res := check(t, checkers.NewScriptChecker("smtp", addr, checkers.SMTPScript(false, nil), true))
+	if res.Status != pb.Status_STATUS_UP || res.Details["banner"] != "mail.test ESMTP" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+
+	res = check(t, checkers.NewScriptChecker("smtp", addr, checkers.SMTPScript(true, &tls.Config{RootCAs: ca.pool}), true))
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s %s", res.Status, res.Message, res.Error)
+	}
+
+	// As with StartTLS, a nil config means the system roots, which do not
+	// include the test CA.
+	res = check(t, checkers.NewScriptChecker("smtp", addr, checkers.SMTPScript(true, nil), true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["step"] != "6" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+}
+
+func TestScriptCheckerStartTLSNilConfig(t *testing.T) {
+	ca := newTestCA(t)
+	addr := serveLines(t, fakeSMTP(&tls.Config{Certificates: []tls.Certificate{ca.issue(t, time.Hour)}}))
+
+	// The test CA is not among the system roots, so the handshake fails
+	// instead of the nil config panicking.
+	res := check(t, checkers.NewScriptChecker("smtp", addr, []checkers.Step{
+		checkers.Expect(`(?m)^220 [^\r\n]*\r\n`),
+		checkers.Send("STARTTLS\r\n"),
+		checkers.Expect(`^220[^\r\n]*\r\n`),
+		checkers.StartTLS(nil),
+	}, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["step"] != "4" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+}
+
+func TestScriptCheckerPresets(t *testing.T) {
+	memcached := serveLines(t, func(conn net.Conn, r *bufio.Reader) {
+		if line, _ := r.ReadString('\n'); line == "version\r\n" {
+			_, _ = io.WriteString(conn, "VERSION 1.6.21\r\n")
+		}
+	})
+	res := check(t, checkers.NewScriptChecker("memcached", memcached, checkers.MemcachedScript(), true))
+	if res.Status != pb.Status_STATUS_UP || res.Details["version"] != "1.6.21" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+
+	nats := serveLines(t, func(conn net.Conn, r *bufio.Reader) {
+		_, _ = io.WriteString(conn, `INFO {"server_id":"x","version":"2.10.7","max_payload":1048576}`+"\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "PING\r\n" {
+				_, _ = io.WriteString(conn, "PONG\r\n")
+			}
+		}
+	})
+	res = check(t, checkers.NewScriptChecker("nats", nats, checkers.NATSScript(), true))
+	if res.Status != pb.Status_STATUS_UP || res.Details["version"] != "2.10.7" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Error)
+	}
+}
+
+func TestScriptCheckerFailure(t *testing.T) {
+	addr := serveLines(t, func(conn net.Conn, r *bufio.Reader) {
+		_, _ = io.WriteString(conn, "421 too busy\r\n")
+		_, _ = r.ReadString('\n')
+	})
+
+	res := check(t, checkers.NewScriptChecker("smtp", addr, checkers.SMTPScript(false, nil), true,
+		checkers.WithScriptTimeout(200*time.Millisecond)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["step"] != "1" || res.Details["received"] != `"421 too busy\r\n"` {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Message)
+	}
+	if !strings.HasPrefix(res.Message, "step 1 (expect /^220") {
+		t.Fatalf("message %q", res.Message)
+	}
+}
+
+func TestScriptCheckerStepTimeout(t *testing.T) {
+	addr := serveLines(t, func(_ net.Conn, r *bufio.Reader) {
+		_, _ = r.ReadString('\n') // never answers
+	})
+
+	start := time.Now()
+	res := check(t, checkers.NewScriptChecker("line", addr, []checkers.Step{
+		checkers.Send("HELLO\r\n"),
+		checkers.Expect(`^OK`).Within(50 * time.Millisecond),
+	}, true))
+	if res.Status != pb.Status_STATUS_DOWN || res.Details["step"] != "2" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("step timeout ignored: took %s", elapsed)
+	}
+}
@@ -0,0 +1,154 @@
+package checkers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// fakeDB is an in-process database/sql driver whose ping and query results
+// can be switched at runtime.
+type fakeDB struct {
+	pingErr  atomic.Pointer[error]
+	queryErr atomic.Pointer[error]
+	queries  atomic.Int32
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+
+func (f *fakeDB) Driver() driver.Driver { return nil }
+
+func (f *fakeDB) failPing(err error)  { f.pingErr.Store(&err) }
+func (f *fakeDB) failQuery(err error) { f.queryErr.Store(&err) }
+
+func loadErr(p *atomic.Pointer[error]) error {
+	if err := p.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) Ping(context.Context) error { return loadErr(&c.db.pingErr) }
+
+func (c fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.db.queries.Add(1)
+	if err := loadErr(&c.db.queryErr); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+// fakeRows returns a single row with the value 1.
+type fakeRows struct{ done bool }
+
+func (r *fakeRows) Columns() []string { return []string{"?column?"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func openFake(t *testing.T) (*fakeDB, *sql.DB) {
+	t.Helper()
+	f := &fakeDB{}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { _ = db.Close() })
+	return f, db
+}
+
+func TestSQLCheckerPing(t *testing.T) {
+	f, db := openFake(t)
+	c := checkers.NewSQLChecker("db", db, true)
+
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+	if res.Details["open"] != "1" || res.Details["idle"] != "1" || res.Details["in_use"] != "0" {
+		t.Fatalf("unexpected stats: %v", res.Details)
+	}
+
+	f.failPing(errors.New("connection refused"))
+	if res := check(t, c); res.Status != pb.Status_STATUS_DOWN || res.Error != "connection refused" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
+
+func TestSQLCheckerValidationQuery(t *testing.T) {
+	f, db := openFake(t)
+	c := checkers.NewSQLChecker("db", db, true, checkers.WithValidationQuery("SELECT 1"))
+
+	if res := check(t, c); res.Status != pb.Status_STATUS_UP || f.queries.Load() != 1 {
+		t.Fatalf("got %s after %d queries", res.Status, f.queries.Load())
+	}
+
+	f.failQuery(errors.New("relation does not exist"))
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_DOWN || res.Message != "validation query failed" {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
+
+func TestSQLCheckerSaturation(t *testing.T) {
+	_, db := openFake(t)
+	db.SetMaxOpenConns(4)
+
+	for range 3 {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+	}
+
+	res := check(t, checkers.NewSQLChecker("db", db, true, checkers.WithMaxSaturation(0.75)))
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["in_use"] != "3" || res.Details["max_open"] != "4" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+}
+
+func TestSQLCheckerWait(t *testing.T) {
+	_, db := openFake(t)
+	db.SetMaxOpenConns(1)
+	c := checkers.NewSQLChecker("db", db, true, checkers.WithMaxWait(10*time.Millisecond))
+
+	if res := check(t, c); res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(50*time.Millisecond, func() { _ = conn.Close() })
+
+	// The check waits for the held connection.
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["wait_count"] != "1" {
+		t.Fatalf("got %s %v: %s", res.Status, res.Details, res.Message)
+	}
+
+	// Without new waits the pool is healthy again.
+	if res := check(t, c); res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+}
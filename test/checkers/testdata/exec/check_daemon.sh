@@ -0,0 +1,5 @@
+#!/bin/sh
+# Prints a status and exits 0, leaving a forked child that still holds
+# stdout.
+(sleep 3) &
+echo "OK - daemon started"
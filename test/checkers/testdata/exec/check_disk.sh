@@ -0,0 +1,2 @@
+#!/bin/sh
+echo "DISK OK - free space: / 3326 MB (56%) | /=2643MB;5948;5958;0;5968 'inode use'=12%;80;90 'it''s'=1"
@@ -0,0 +1,4 @@
+#!/bin/sh
+# Exits with the code given as the first argument and reports where it ran.
+echo "LEVEL $LEVEL in $(basename "$PWD") | level=$LEVEL"
+exit "$1"
@@ -0,0 +1,5 @@
+#!/bin/sh
+# Forks a child that creates the file $1 unless it is killed along with the
+# plugin, then waits for it.
+(sleep 0.5; touch "$1") &
+wait
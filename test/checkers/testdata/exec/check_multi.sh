@@ -0,0 +1,6 @@
+#!/bin/sh
+echo "QUEUE CRITICAL - 3 queues stuck | stuck=3;1;2"
+echo "orders: 1200 messages"
+echo "emails: 900 messages | orders=1200c emails=900c"
+echo "broker unreachable" >&2
+exit 2
@@ -0,0 +1,123 @@
+package checkers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon/checkers"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+)
+
+// gauge is a value function reading whatever the test stored last.
+type gauge struct{ bits atomic.Uint64 }
+
+func (g *gauge) set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+func (g *gauge) read(context.Context) (float64, error) {
+	return math.Float64frombits(g.bits.Load()), nil
+}
+
+func TestThresholdCheckerBounds(t *testing.T) {
+	var g gauge
+	backlog := checkers.NewThresholdChecker("backlog", g.read, false,
+		checkers.WithAbove(100, 1000), checkers.WithUnit("items"))
+	hitRate := checkers.NewThresholdChecker("cache", g.read, false,
+		checkers.WithBelow(0.8, 0.5), checkers.WithAbove(1.5, 2))
+
+	for _, tc := range []struct {
+		c       *checkers.ThresholdChecker
+		value   float64
+		want    pb.Status
+		message string
+	}{
+		{backlog, 12, pb.Status_STATUS_UP, "12 items"},
+		{backlog, 100, pb.Status_STATUS_DEGRADED, "100 items at or above warning 100 items"},
+		{backlog, 2500.5, pb.Status_STATUS_DOWN, "2500.5 items at or above critical 1000 items"},
+		{hitRate, 0.95, pb.Status_STATUS_UP, "0.95"},
+		{hitRate, 0.6, pb.Status_STATUS_DEGRADED, "0.6 at or below warning 0.8"},
+		{hitRate, 0.1234, pb.Status_STATUS_DOWN, "0.123 at or below critical 0.5"},
+		{hitRate, 1.7, pb.Status_STATUS_DEGRADED, "1.7 at or above warning 1.5"},
+	} {
+		g.set(tc.value)
+		res := check(t, tc.c)
+		if res.Status != tc.want || res.Message != tc.message {
+			t.Errorf("%s(%v): got %s %q, want %s %q", tc.c.Name(), tc.value, res.Status, res.Message, tc.want, tc.message)
+		}
+	}
+
+	g.set(12)
+	res := check(t, backlog)
+	for key, want := range map[string]string{
+		"value":          "12",
+		"unit":           "items",
+		"warning_above":  "100",
+		"critical_above": "1000",
+	} {
+		if res.Details[key] != want {
+			t.Errorf("details[%s] = %q, want %q", key, res.Details[key], want)
+		}
+	}
+}
+
+func TestThresholdCheckerRollingAverage(t *testing.T) {
+	var g gauge
+	c := checkers.NewThresholdChecker("latency", g.read, false,
+		checkers.WithAbove(50, 100), checkers.WithUnit("ms"), checkers.WithRollingAverage(time.Minute))
+
+	// A single spike does not trip the warning while the average stays low.
+	for _, v := range []float64{10, 20, 90} {
+		g.set(v)
+		if res := check(t, c); res.Status != pb.Status_STATUS_UP {
+			t.Fatalf("%v: got %s: %s", v, res.Status, res.Message)
+		}
+	}
+	g.set(120)
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["value"] != "60" || res.Details["raw"] != "120" || res.Details["samples"] != "4" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestThresholdCheckerRate(t *testing.T) {
+	var g gauge
+	c := checkers.NewThresholdChecker("failures", g.read, false,
+		checkers.WithAbove(100, 1e6), checkers.WithRate(0))
+
+	g.set(1000)
+	if res := check(t, c); res.Status != pb.Status_STATUS_UNKNOWN {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	g.set(1001)
+	if res := check(t, c); res.Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s: %s", res.Status, res.Message)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	g.set(1101) // 100 in ~50ms, about 2000/s
+	res := check(t, c)
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["samples"] != "2" {
+		t.Fatalf("got %s: %s %v", res.Status, res.Message, res.Details)
+	}
+}
+
+func TestThresholdCheckerFailures(t *testing.T) {
+	res := check(t, checkers.NewThresholdChecker("queue", func(context.Context) (float64, error) {
+		return 0, errors.New("broker unavailable")
+	}, false, checkers.WithAbove(1, 2)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != "broker unavailable" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+
+	res = check(t, checkers.NewThresholdChecker("queue", func(context.Context) (float64, error) {
+		return math.NaN(), nil
+	}, false, checkers.WithAbove(1, 2)))
+	if res.Status != pb.Status_STATUS_DOWN || res.Error != "value is NaN" {
+		t.Fatalf("got %s: %s", res.Status, res.Error)
+	}
+}
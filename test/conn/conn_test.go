@@ -0,0 +1,162 @@
+package conn
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// serveHealth starts a grpc.health.v1 server on addr ("127.0.0.1:0" for any port).
+func serveHealth(t *testing.T, addr string) (*grpc.Server, *health.Server, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := health.NewServer()
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, hs)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return s, hs, lis.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// eventually polls c until it reports want.
+func eventually(t *testing.T, c srvmon.Checker, want pb.Status) *pb.CheckResult {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		res, err := c.Check(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status == want {
+			return res
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status %s, want %s: %s %s", res.Status, want, res.Message, res.Error)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchTransitions(t *testing.T) {
+	_, hs, addr := serveHealth(t, "127.0.0.1:0")
+	hs.SetServingStatus("api", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	c := srvmon.NewConnChecker(dial(t, addr), "api", true,
+		srvmon.WithService("api"), srvmon.WithWatch())
+	t.Cleanup(func() { _ = c.Close() })
+
+	res := eventually(t, c, pb.Status_STATUS_UP)
+	if res.Details["mode"] != "watch" {
+		t.Fatalf("unexpected details: %v", res.Details)
+	}
+
+	hs.SetServingStatus("api", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	eventually(t, c, pb.Status_STATUS_DOWN)
+}
+
+func TestWatchServices(t *testing.T) {
+	_, hs, addr := serveHealth(t, "127.0.0.1:0")
+	hs.SetServingStatus("users", grpc_health_v1.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	c := srvmon.NewConnChecker(dial(t, addr), "backend", true,
+		srvmon.WithServices("users", "orders"), srvmon.WithWatch())
+	t.Cleanup(func() { _ = c.Close() })
+
+	res := eventually(t, c, pb.Status_STATUS_DOWN)
+	if len(res.Children) != 2 || res.Children[0].Name != "users" || res.Children[0].Status != pb.Status_STATUS_UP {
+		t.Fatalf("unexpected children: %v", res.Children)
+	}
+	if res.Message != "1/2 services serving" {
+		t.Fatalf("message %q", res.Message)
+	}
+
+	hs.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_SERVING)
+	eventually(t, c, pb.Status_STATUS_UP)
+}
+
+func TestWatchReconnect(t *testing.T) {
+	s, hs, addr := serveHealth(t, "127.0.0.1:0")
+	hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	c := srvmon.NewConnChecker(dial(t, addr), "api", true,
+		srvmon.WithWatch(), srvmon.WithWatchBackoff(10*time.Millisecond, 50*time.Millisecond))
+	t.Cleanup(func() { _ = c.Close() })
+
+	eventually(t, c, pb.Status_STATUS_UP)
+
+	s.Stop()
+	res := eventually(t, c, pb.Status_STATUS_DEGRADED)
+	if res.Message != "reconnecting" || res.Details["last_status"] != "SERVING" {
+		t.Fatalf("got %q %v", res.Message, res.Details)
+	}
+
+	serveHealth(t, addr)
+	res = eventually(t, c, pb.Status_STATUS_UP)
+	if res.Details["reconnects"] == "0" {
+		t.Fatalf("reconnects not counted: %v", res.Details)
+	}
+}
+
+func TestWatchBrokenTooLong(t *testing.T) {
+	s, hs, addr := serveHealth(t, "127.0.0.1:0")
+	hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	c := srvmon.NewConnChecker(dial(t, addr), "api", true, srvmon.WithWatch(),
+		srvmon.WithWatchBackoff(10*time.Millisecond, 50*time.Millisecond), srvmon.WithWatchGrace(200*time.Millisecond))
+	t.Cleanup(func() { _ = c.Close() })
+
+	eventually(t, c, pb.Status_STATUS_UP)
+
+	s.Stop()
+	eventually(t, c, pb.Status_STATUS_DEGRADED)
+	res := eventually(t, c, pb.Status_STATUS_DOWN)
+	if !strings.HasPrefix(res.Message, "watch broken for") || res.Details["last_status"] != "SERVING" {
+		t.Fatalf("got %q %v", res.Message, res.Details)
+	}
+}
+
+func TestWatchBackoffRejectsZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("zero backoff accepted")
+		}
+	}()
+	srvmon.WithWatchBackoff(0, time.Second)
+}
+
+func TestUnaryServices(t *testing.T) {
+	_, hs, addr := serveHealth(t, "127.0.0.1:0")
+	hs.SetServingStatus("users", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	c := srvmon.NewConnChecker(dial(t, addr), "backend", true, srvmon.WithServices("users", "missing"))
+	res, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != pb.Status_STATUS_DOWN || len(res.Children) != 2 || res.Children[0].Status != pb.Status_STATUS_UP {
+		t.Fatalf("got %s %v", res.Status, res.Children)
+	}
+}
@@ -0,0 +1,120 @@
+package federation
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type stubChecker struct {
+	name   string
+	status pb.Status
+}
+
+func (c stubChecker) Name() string { return c.name }
+
+func (c stubChecker) MustOK(_ context.Context) bool { return false }
+
+func (c stubChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	return &pb.CheckResult{Name: c.name, Status: c.status, Timestamp: timestamppb.Now()}, nil
+}
+
+// serve exposes m over gRPC and returns a client connection to it.
+func serve(t *testing.T, m *srvmon.SrvMon) *grpc.ClientConn {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterSrvmonServer(s, m)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestGRPCFederation(t *testing.T) {
+	remote := srvmon.New(srvmon.Config{Version: "2.0.0"}, zap.NewNop(),
+		stubChecker{name: "redis", status: pb.Status_STATUS_UP},
+		stubChecker{name: "kafka", status: pb.Status_STATUS_DOWN},
+	)
+	conn := serve(t, remote)
+
+	res, err := srvmon.NewSrvmonChecker(conn, "billing", true, srvmon.WithRemoteChecks("billing/")).Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != pb.Status_STATUS_DEGRADED || res.Details["version"] != "2.0.0" {
+		t.Fatalf("got %s %v", res.Status, res.Details)
+	}
+	if len(res.Children) != 2 || res.Children[1].Name != "billing/kafka" {
+		t.Fatalf("unexpected children: %v", res.Children)
+	}
+}
+
+func TestHTTPFederation(t *testing.T) {
+	remote := srvmon.New(srvmon.Config{Version: "2.0.0"}, zap.NewNop(),
+		stubChecker{name: "redis", status: pb.Status_STATUS_UP},
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := remote.Health(r.Context(), &pb.HealthRequest{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := protojson.Marshal(resp)
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	res, err := srvmon.NewSrvmonHTTPChecker(srv.URL, "billing", true).Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != pb.Status_STATUS_UP || len(res.Children) != 0 {
+		t.Fatalf("got %s, %d children", res.Status, len(res.Children))
+	}
+}
+
+func TestFederationLoop(t *testing.T) {
+	a := srvmon.New(srvmon.Config{Version: "a"}, zap.NewNop())
+	b := srvmon.New(srvmon.Config{Version: "b"}, zap.NewNop())
+	connA, connB := serve(t, a), serve(t, b)
+
+	a.AddDependencies(srvmon.NewSrvmonChecker(connB, "b", false, srvmon.WithRemoteChecks("b/")))
+	b.AddDependencies(srvmon.NewSrvmonChecker(connA, "a", false))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := a.Health(ctx, &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a -> b -> a: the second visit to a answers UNKNOWN instead of recursing.
+	viaB := resp.Checks[0]
+	if viaB.Status != pb.Status_STATUS_UP || len(viaB.Children) != 1 {
+		t.Fatalf("got %s %v", viaB.Status, viaB.Children)
+	}
+	if back := viaB.Children[0]; back.Name != "b/a" || back.Status != pb.Status_STATUS_UNKNOWN {
+		t.Fatalf("loop not cut: %v", back)
+	}
+}
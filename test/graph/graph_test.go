@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type stubChecker struct {
+	name   string
+	status pb.Status
+	calls  int
+}
+
+func (c *stubChecker) Name() string { return c.name }
+
+func (c *stubChecker) MustOK(_ context.Context) bool { return true }
+
+func (c *stubChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	c.calls++
+	return &pb.CheckResult{Name: c.name, Status: c.status, Timestamp: timestamppb.Now()}, nil
+}
+
+func TestSkipOnParentDown(t *testing.T) {
+	dns := &stubChecker{name: "dns", status: pb.Status_STATUS_DOWN}
+	db := &stubChecker{name: "postgres", status: pb.Status_STATUS_UP}
+	cache := &stubChecker{name: "cache", status: pb.Status_STATUS_UP}
+
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), cache, db, dns)
+	if err := m.DependsOn("postgres", "dns"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DependsOn("cache", "postgres"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := m.Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if db.calls != 0 || cache.calls != 0 {
+		t.Fatalf("children executed: postgres=%d cache=%d", db.calls, cache.calls)
+	}
+	if resp.Checks[0].Name != "cache" || resp.Checks[2].Name != "dns" {
+		t.Fatalf("registration order not kept: %v", resp.Checks)
+	}
+	for _, c := range resp.Checks[:2] {
+		if c.Status != pb.Status_STATUS_UNKNOWN || c.Message != "skipped: parent dns down" {
+			t.Fatalf("%s: got %s %q", c.Name, c.Status, c.Message)
+		}
+	}
+	if resp.Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("overall status: got %s", resp.Status)
+	}
+}
+
+func TestCycleDetection(t *testing.T) {
+	m := srvmon.New(srvmon.Config{}, zap.NewNop())
+	if err := m.DependsOn("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DependsOn("b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DependsOn("c", "a"); !errors.Is(err, srvmon.ErrDependencyCycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	if err := m.DependsOn("a", "a"); !errors.Is(err, srvmon.ErrDependencyCycle) {
+		t.Fatalf("expected self-cycle error, got %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(),
+		&stubChecker{name: "dns"}, &stubChecker{name: "postgres"})
+	if err := m.DependsOn("postgres", "dns"); err != nil {
+		t.Fatal(err)
+	}
+
+	if dot := m.DOT(); !strings.Contains(dot, `"dns" -> "postgres";`) {
+		t.Fatalf("dot:\n%s", dot)
+	}
+	if mm := m.Mermaid(); !strings.Contains(mm, "n0 --> n1") {
+		t.Fatalf("mermaid:\n%s", mm)
+	}
+}
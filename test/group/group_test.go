@@ -0,0 +1,64 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type stubChecker struct {
+	status pb.Status
+	err    error
+}
+
+func (c stubChecker) MustOK(_ context.Context) bool { return true }
+
+func (c stubChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &pb.CheckResult{Name: "replica", Status: c.status, Timestamp: timestamppb.Now()}, nil
+}
+
+var (
+	up   = stubChecker{status: pb.Status_STATUS_UP}
+	down = stubChecker{status: pb.Status_STATUS_DOWN}
+	fail = stubChecker{err: errors.New("boom")}
+)
+
+func TestPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  srvmon.GroupPolicy
+		members []srvmon.Checker
+		want    pb.Status
+	}{
+		{"all up", srvmon.AllOf(), []srvmon.Checker{up, up, up}, pb.Status_STATUS_UP},
+		{"all partial", srvmon.AllOf(), []srvmon.Checker{up, down, up}, pb.Status_STATUS_DOWN},
+		{"any partial", srvmon.AnyOf(), []srvmon.Checker{down, up, fail}, pb.Status_STATUS_DEGRADED},
+		{"any none", srvmon.AnyOf(), []srvmon.Checker{down, fail}, pb.Status_STATUS_DOWN},
+		{"quorum met", srvmon.AtLeast(2), []srvmon.Checker{up, down, up}, pb.Status_STATUS_DEGRADED},
+		{"quorum lost", srvmon.AtLeast(2), []srvmon.Checker{up, down, down}, pb.Status_STATUS_DOWN},
+		{"percent met", srvmon.PercentHealthy(60), []srvmon.Checker{up, up, up, down, down}, pb.Status_STATUS_DEGRADED},
+		{"percent lost", srvmon.PercentHealthy(60), []srvmon.Checker{up, up, down, down, down}, pb.Status_STATUS_DOWN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := srvmon.NewGroupChecker("group", true, tt.policy, tt.members...).Check(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != tt.want {
+				t.Fatalf("got %s, want %s (%s)", res.Status, tt.want, res.Message)
+			}
+			if len(res.Children) != len(tt.members) {
+				t.Fatalf("got %d children, want %d", len(res.Children), len(tt.members))
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package run
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHealthCoalesced(t *testing.T) {
+	db := &slowChecker{name: "postgres"}
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), db)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Health(context.Background(), &pb.HealthRequest{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := db.calls.Load(); n != 1 {
+		t.Fatalf("postgres checked %d times, want 1", n)
+	}
+}
+
+func TestResultTTL(t *testing.T) {
+	db := &slowChecker{name: "postgres"}
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), db).SetResultTTL("postgres", time.Second)
+
+	first, err := m.Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Checks[0].Cached {
+		t.Fatal("first result marked as cached")
+	}
+
+	second, err := m.Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Checks[0].Cached || second.Checks[0].Age.AsDuration() <= 0 {
+		t.Fatalf("second result not cached: %v", second.Checks[0])
+	}
+	if n := db.calls.Load(); n != 1 {
+		t.Fatalf("postgres checked %d times, want 1", n)
+	}
+
+	if _, err := m.RunChecks(context.Background(), &pb.RunChecksRequest{Names: []string{"postgres"}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := db.calls.Load(); n != 2 {
+		t.Fatalf("RunChecks served from cache: %d calls", n)
+	}
+}
+
+// blockingChecker only returns once its context is done.
+type blockingChecker struct {
+	calls atomic.Int32
+}
+
+func (c *blockingChecker) Name() string { return "stuck" }
+
+func (c *blockingChecker) MustOK(_ context.Context) bool { return true }
+
+func (c *blockingChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	c.calls.Add(1)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestBlockedCheckReleased(t *testing.T) {
+	stuck := &blockingChecker{}
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), stuck)
+
+	for i := range 2 {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := m.Health(ctx, &pb.HealthRequest{})
+		cancel()
+		if err == nil {
+			t.Fatalf("call %d: expected an error from the blocked check", i)
+		}
+		// Let the shared execution observe its own deadline.
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n := stuck.calls.Load(); n != 2 {
+		t.Fatalf("stuck checked %d times, want 2: the first execution never finished", n)
+	}
+}
+
+// trailChecker reports UNKNOWN when the request came with a federation trail,
+// as a SrvmonChecker does on a loop. It blocks each call until released.
+type trailChecker struct {
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (c *trailChecker) Name() string { return "remote" }
+
+func (c *trailChecker) MustOK(_ context.Context) bool { return true }
+
+func (c *trailChecker) Check(ctx context.Context) (*pb.CheckResult, error) {
+	c.calls.Add(1)
+	c.started <- struct{}{}
+	<-c.release
+
+	res := &pb.CheckResult{Name: "remote", Status: pb.Status_STATUS_UP}
+	if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("x-srvmon-trail")) > 0 {
+		res.Status = pb.Status_STATUS_UNKNOWN
+	}
+	return res, nil
+}
+
+func TestForwardedResultNotShared(t *testing.T) {
+	c := &trailChecker{started: make(chan struct{}, 2), release: make(chan struct{})}
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), c).SetResultTTL("remote", time.Hour)
+	fwd := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-srvmon-trail", "upstream"))
+
+	results := make(chan *pb.HealthResponse, 2)
+	health := func(ctx context.Context) {
+		resp, err := m.Health(ctx, &pb.HealthRequest{})
+		if err != nil {
+			t.Error(err)
+		}
+		results <- resp
+	}
+	go health(fwd)
+	<-c.started
+	go health(context.Background())
+	select {
+	case <-c.started:
+	case <-time.After(time.Second):
+		t.Fatal("local request joined the forwarded execution")
+	}
+	close(c.release)
+
+	got := map[pb.Status]int{}
+	for range 2 {
+		got[(<-results).Checks[0].Status]++
+	}
+	if got[pb.Status_STATUS_UP] != 1 || got[pb.Status_STATUS_UNKNOWN] != 1 {
+		t.Fatalf("got %v, want one UP and one UNKNOWN", got)
+	}
+
+	resp, err := m.Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check := resp.Checks[0]; check.Status != pb.Status_STATUS_UP || !check.Cached {
+		t.Fatalf("got %s cached=%v", check.Status, check.Cached)
+	}
+	if n := c.calls.Load(); n != 2 {
+		t.Fatalf("checked %d times, want 2", n)
+	}
+}
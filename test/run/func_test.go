@@ -0,0 +1,109 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+)
+
+// bareChecker returns only a status and declares its metadata through the
+// optional interfaces.
+type bareChecker struct{}
+
+func (bareChecker) Name() string { return "queue" }
+
+func (bareChecker) Description() string { return "job queue backlog" }
+
+func (bareChecker) Tags() []string { return []string{"queue"} }
+
+func (bareChecker) MustOK(_ context.Context) bool { return false }
+
+func (bareChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	return &pb.CheckResult{Status: pb.Status_STATUS_UP}, nil
+}
+
+func TestNewCheck(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		status  pb.Status
+		msg     string
+		err     error
+		want    pb.Status
+		wantMsg string
+	}{
+		{"up", pb.Status_STATUS_UP, "fine", nil, pb.Status_STATUS_UP, "fine"},
+		{"unspecified", pb.Status_STATUS_UNSPECIFIED, "", nil, pb.Status_STATUS_UP, ""},
+		{"error", pb.Status_STATUS_UNSPECIFIED, "", errors.New("refused"), pb.Status_STATUS_DOWN, "check failed"},
+		{"degraded error", pb.Status_STATUS_DEGRADED, "slow", errors.New("timeout"), pb.Status_STATUS_DEGRADED, "slow"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := srvmon.NewCheck("db", func(context.Context) (pb.Status, string, error) {
+				return tc.status, tc.msg, tc.err
+			})
+			res, err := c.Check(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Name != "db" || res.Status != tc.want || res.Message != tc.wantMsg {
+				t.Fatalf("got %s %s: %s", res.Name, res.Status, res.Message)
+			}
+			if tc.err != nil && res.Error != tc.err.Error() {
+				t.Errorf("error = %q", res.Error)
+			}
+			if res.Timestamp == nil || res.Duration == nil {
+				t.Errorf("timestamp or duration missing: %v", res)
+			}
+		})
+	}
+}
+
+func TestCheckMetadata(t *testing.T) {
+	db := srvmon.NewCheck("postgres", func(context.Context) (pb.Status, string, error) {
+		return pb.Status_STATUS_UP, "", nil
+	}, srvmon.WithDescription("primary database"), srvmon.WithTags("database", "critical-path"))
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), db, bareChecker{})
+
+	resp, err := m.Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg, queue := resp.Checks[0], resp.Checks[1]
+	if pg.Description != "primary database" || !slices.Equal(pg.Tags, []string{"database", "critical-path"}) {
+		t.Errorf("postgres metadata: %q %v", pg.Description, pg.Tags)
+	}
+	if queue.Name != "queue" || queue.Description != "job queue backlog" || !slices.Equal(queue.Tags, []string{"queue"}) {
+		t.Errorf("queue metadata: %v", queue)
+	}
+	if queue.Timestamp == nil || queue.Duration == nil {
+		t.Errorf("queue timestamp or duration missing: %v", queue)
+	}
+}
+
+func TestCheckInterval(t *testing.T) {
+	var calls atomic.Int32
+	fn := func(context.Context) (pb.Status, string, error) {
+		calls.Add(1)
+		return pb.Status_STATUS_UP, "", nil
+	}
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(),
+		srvmon.NewCheck("cache", fn, srvmon.WithInterval(time.Minute)),
+		srvmon.NewCheck("broker", fn, srvmon.WithInterval(time.Minute)),
+	).SetResultTTL("broker", 0)
+
+	for range 3 {
+		if _, err := m.Health(context.Background(), &pb.HealthRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// cache runs once and is then reused; broker's explicit zero TTL wins.
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("checks executed %d times, want 4", n)
+	}
+}
@@ -0,0 +1,74 @@
+package run
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type slowChecker struct {
+	name  string
+	calls atomic.Int32
+}
+
+func (c *slowChecker) Name() string { return c.name }
+
+func (c *slowChecker) MustOK(_ context.Context) bool { return true }
+
+func (c *slowChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	c.calls.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	return &pb.CheckResult{Name: c.name, Status: pb.Status_STATUS_UP, Timestamp: timestamppb.Now()}, nil
+}
+
+func TestRunChecksCoalesced(t *testing.T) {
+	db := &slowChecker{name: "postgres"}
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), db, &slowChecker{name: "cache"})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := m.RunChecks(context.Background(), &pb.RunChecksRequest{Names: []string{"postgres"}})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(resp.Checks) != 1 || resp.Checks[0].Name != "postgres" {
+				t.Errorf("unexpected checks: %v", resp.Checks)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := db.calls.Load(); n != 1 {
+		t.Fatalf("postgres checked %d times, want 1", n)
+	}
+}
+
+func TestRunChecksAll(t *testing.T) {
+	m := srvmon.New(srvmon.Config{}, zap.NewNop(), &slowChecker{name: "postgres"}, &slowChecker{name: "cache"})
+
+	resp, err := m.RunChecks(context.Background(), &pb.RunChecksRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Checks) != 2 || resp.Checks[1].Name != "cache" {
+		t.Fatalf("unexpected checks: %v", resp.Checks)
+	}
+
+	_, err = m.RunChecks(context.Background(), &pb.RunChecksRequest{Names: []string{"missing"}})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func dialGRPC(t *testing.T, addr string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func withToken(token string) context.Context {
+	if token == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAdminGRPCAuth(t *testing.T) {
+	_, addr := serve(t, srvmon.Config{AdminToken: "s3cret"}, nil, &stubChecker{name: "postgres", status: pb.Status_STATUS_UP})
+	conn := dialGRPC(t, addr)
+	admin := pb.NewAdminClient(conn)
+	req := &pb.SetMaintenanceRequest{Check: "postgres", Reason: "vacuum"}
+
+	for _, token := range []string{"", "wrong"} {
+		if _, err := admin.SetMaintenance(withToken(token), req); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("token %q: got %v, want Unauthenticated", token, err)
+		}
+	}
+	if _, err := admin.SetMaintenance(withToken("s3cret"), req); err != nil {
+		t.Fatal(err)
+	}
+
+	// Non-admin RPCs need no token.
+	resp, err := pb.NewSrvmonClient(conn).Health(context.Background(), &pb.HealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Checks[0].GetOverride().GetMaintenance() {
+		t.Fatalf("maintenance not applied: %v", resp.Checks[0])
+	}
+}
+
+func TestAdminRESTAuth(t *testing.T) {
+	addr, _ := serve(t, srvmon.Config{AdminToken: "s3cret"}, nil, &stubChecker{name: "postgres", status: pb.Status_STATUS_UP})
+	url := "http://" + addr + "/admin/maintenance"
+	body := `{"check":"postgres","reason":"vacuum"}`
+
+	for _, token := range []string{"", "wrong"} {
+		if code, _ := do(t, http.MethodPost, url, token, strings.NewReader(body)); code != http.StatusUnauthorized {
+			t.Fatalf("token %q: got %d, want 401", token, code)
+		}
+	}
+	if code, resp := do(t, http.MethodPost, url, "s3cret", strings.NewReader(body)); code != http.StatusOK {
+		t.Fatalf("got %d: %s", code, resp)
+	}
+
+	if code, _ := do(t, http.MethodGet, "http://"+addr+"/health", "", nil); code != http.StatusOK {
+		t.Fatalf("health: got %d", code)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestRunRoutes(t *testing.T) {
+	addr, _ := serve(t, srvmon.Config{}, nil,
+		&stubChecker{name: "postgres", status: pb.Status_STATUS_UP},
+		&stubChecker{name: "cache", status: pb.Status_STATUS_DOWN})
+	url := "http://" + addr
+
+	for name, body := range map[string]io.Reader{
+		"no body": nil,
+		// An unknown-length reader is sent chunked, with ContentLength -1.
+		"empty chunked body": io.MultiReader(strings.NewReader("")),
+		"empty object":       strings.NewReader("{}"),
+	} {
+		code, data := do(t, http.MethodPost, url+"/checks/run", "", body)
+		resp := &pb.RunChecksResponse{}
+		if code != http.StatusOK || protojson.Unmarshal([]byte(data), resp) != nil || len(resp.Checks) != 2 {
+			t.Fatalf("%s: got %d %s", name, code, data)
+		}
+	}
+
+	code, data := do(t, http.MethodPost, url+"/checks/run", "", strings.NewReader(`{"names":["cache"]}`))
+	resp := &pb.RunChecksResponse{}
+	if code != http.StatusOK || protojson.Unmarshal([]byte(data), resp) != nil ||
+		len(resp.Checks) != 1 || resp.Checks[0].Status != pb.Status_STATUS_DOWN {
+		t.Fatalf("got %d %s", code, data)
+	}
+	if code, data = do(t, http.MethodPost, url+"/checks/run", "", strings.NewReader("not json")); code != http.StatusBadRequest {
+		t.Fatalf("bad body: got %d %s", code, data)
+	}
+
+	code, data = do(t, http.MethodPost, url+"/checks/postgres/run", "", nil)
+	res := &pb.CheckResult{}
+	if code != http.StatusOK || protojson.Unmarshal([]byte(data), res) != nil || res.Name != "postgres" {
+		t.Fatalf("got %d %s", code, data)
+	}
+	if code, _ = do(t, http.MethodPost, url+"/checks/missing/run", "", nil); code != http.StatusNotFound {
+		t.Fatalf("missing: got %d, want 404", code)
+	}
+	if code, _ = do(t, http.MethodGet, url+"/checks/run", "", nil); code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET: got %d, want 405", code)
+	}
+}
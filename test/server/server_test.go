@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4bb4t/srvmon"
+	pb "github.com/s4bb4t/srvmon/pkg/grpc/srvmon/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type stubChecker struct {
+	name   string
+	status pb.Status
+}
+
+func (c *stubChecker) Name() string { return c.name }
+
+func (c *stubChecker) MustOK(_ context.Context) bool { return true }
+
+func (c *stubChecker) Check(_ context.Context) (*pb.CheckResult, error) {
+	return &pb.CheckResult{Name: c.name, Status: c.status, Timestamp: timestamppb.Now()}, nil
+}
+
+// freeAddr returns a local address nobody listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	_ = lis.Close()
+	return addr
+}
+
+// serve runs m until the test ends and returns its REST and gRPC addresses
+// once both accept connections.
+func serve(t *testing.T, cfg srvmon.Config, setup func(m *srvmon.SrvMon), deps ...srvmon.Checker) (httpAddr, grpcAddr string) {
+	t.Helper()
+	cfg.HTTPAddress, cfg.GRPCAddress = freeAddr(t), freeAddr(t)
+	m := srvmon.New(cfg, zap.NewNop(), deps...)
+	if setup != nil {
+		setup(m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	for _, addr := range []string{cfg.HTTPAddress, cfg.GRPCAddress} {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				_ = conn.Close()
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s not listening: %v", addr, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	return cfg.HTTPAddress, cfg.GRPCAddress
+}
+
+// do sends a request to the REST server and returns the status code and body.
+func do(t *testing.T, method, url, token string, body io.Reader) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestGraphRoute(t *testing.T) {
+	addr, _ := serve(t, srvmon.Config{}, func(m *srvmon.SrvMon) {
+		if err := m.DependsOn("postgres", "dns"); err != nil {
+			t.Fatal(err)
+		}
+	}, &stubChecker{name: "dns"}, &stubChecker{name: "postgres"})
+
+	code, body := do(t, http.MethodGet, "http://"+addr+"/health/graph", "", nil)
+	if code != http.StatusOK || !strings.Contains(body, `"dns" -> "postgres";`) {
+		t.Fatalf("dot: %d\n%s", code, body)
+	}
+	code, body = do(t, http.MethodGet, "http://"+addr+"/health/graph?format=mermaid", "", nil)
+	if code != http.StatusOK || !strings.Contains(body, "n0 --> n1") {
+		t.Fatalf("mermaid: %d\n%s", code, body)
+	}
+	if code, _ = do(t, http.MethodGet, "http://"+addr+"/health/graph?format=svg", "", nil); code != http.StatusBadRequest {
+		t.Fatalf("svg: got %d, want 400", code)
+	}
+}